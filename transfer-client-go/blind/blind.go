@@ -0,0 +1,107 @@
+package blind
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+)
+
+// 隐藏产品ID
+// 产品密钥由盐值与真实产品ID确定性派生,隐藏产品ID为产品密钥公钥的SHA256十六进制文本。
+// 合约只看到隐藏产品ID,持有盐值与真实产品ID的一方可以随时重新派生产品密钥证明自己知道产品ID。
+
+const keyLabel = "BPOTS-tid-key"
+
+// Registry 客户端保存的盐值与隐藏产品ID到真实产品ID的映射
+type Registry struct {
+	Salt []byte            `json:"salt"`
+	Tids map[string]string `json:"tids"`
+}
+
+// NewSalt 生成新的32字节盐值,同一供应链的参与方共享同一盐值
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, 32)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+func NewRegistry(salt []byte) *Registry {
+	return &Registry{Salt: salt, Tids: make(map[string]string)}
+}
+
+// LoadRegistry 从文件读取映射
+func LoadRegistry(filename string) (*Registry, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	registry := new(Registry)
+	err = json.Unmarshal(content, registry)
+	if err != nil {
+		return nil, err
+	}
+	if registry.Tids == nil {
+		registry.Tids = make(map[string]string)
+	}
+	return registry, nil
+}
+
+// Save 将映射保存到文件
+func (r *Registry) Save(filename string) error {
+	content, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, content, 0600)
+}
+
+// TidKey 派生真实产品ID对应的产品密钥
+func (r *Registry) TidKey(tid string) *ecdsa.PrivateKey {
+	return deriveKey([]byte(keyLabel), r.Salt, []byte(tid))
+}
+
+// deriveKey 由各部分内容的SHA256确定性派生P-256私钥
+func deriveKey(parts ...[]byte) *ecdsa.PrivateKey {
+	curve := elliptic.P256()
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write(part)
+	}
+	d := new(big.Int).SetBytes(hash.Sum(nil))
+	n := new(big.Int).Sub(curve.Params().N, big.NewInt(1))
+	d.Mod(d, n)
+	d.Add(d, big.NewInt(1))
+	sk := new(ecdsa.PrivateKey)
+	sk.PublicKey.Curve = curve
+	sk.D = d
+	sk.PublicKey.X, sk.PublicKey.Y = curve.ScalarBaseMult(d.Bytes())
+	return sk
+}
+
+// Blind 计算真实产品ID的隐藏产品ID与产品密钥公钥,并记录映射
+func (r *Registry) Blind(tid string) (string, []byte, error) {
+	sk := r.TidKey(tid)
+	pkBytes, err := x509.MarshalPKIXPublicKey(&sk.PublicKey)
+	if err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(pkBytes)
+	blinded := hex.EncodeToString(sum[:])
+	r.Tids[blinded] = tid
+	return blinded, pkBytes, nil
+}
+
+// Lookup 查询隐藏产品ID对应的真实产品ID
+func (r *Registry) Lookup(blinded string) (string, bool) {
+	tid, ok := r.Tids[blinded]
+	return tid, ok
+}
@@ -0,0 +1,49 @@
+package blind
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"strconv"
+	"transfer-client-go/sign"
+)
+
+// 所有者承诺
+// 保密模式下合约把产品所有者记录为承诺ID,即"c."加一次性公钥的SHA256十六进制文本。
+// 一次性密钥由伪ID私钥、产品ID与接收时的所有权记录序号确定性派生,每个产品、每次接收都不同,
+// 伪ID持有者随时可以重新派生,并可用伪ID私钥对一次性公钥签名向第三方打开承诺。
+
+const (
+	ownerLabel        = "BPOTS-owner-key"
+	CommitOwnerPrefix = "c."
+)
+
+// OwnerKey 派生伪ID在产品tid第counter条所有权记录上使用的一次性密钥
+func OwnerKey(pidSk *ecdsa.PrivateKey, tid string, counter int) *ecdsa.PrivateKey {
+	return deriveKey([]byte(ownerLabel), pidSk.D.Bytes(), []byte(tid), []byte(strconv.Itoa(counter)))
+}
+
+// CommitOwnerId 计算一次性公钥对应的承诺ID,同时返回公钥编码
+func CommitOwnerId(pk *ecdsa.PublicKey) (string, []byte, error) {
+	pkBytes, err := x509.MarshalPKIXPublicKey(pk)
+	if err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(pkBytes)
+	return CommitOwnerPrefix + hex.EncodeToString(sum[:]), pkBytes, nil
+}
+
+// OpenOwner 伪ID对一次性公钥签名,证明承诺ID属于该伪ID
+func OpenOwner(pidSk *ecdsa.PrivateKey, ownerPk []byte) ([]byte, []byte, error) {
+	return sign.Sign(ownerPk, pidSk)
+}
+
+// VerifyOwnerOpening 验证承诺ID由ownerPk确定且ownerPk经伪ID公钥签名
+func VerifyOwnerOpening(id string, pidPk *ecdsa.PublicKey, ownerPk, r, s []byte) bool {
+	sum := sha256.Sum256(ownerPk)
+	if id != CommitOwnerPrefix+hex.EncodeToString(sum[:]) {
+		return false
+	}
+	return sign.Verify(ownerPk, r, s, pidPk)
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"os"
+	"strconv"
+	"transfer-client-go/merkle"
+	"transfer-client-go/sign"
+	"transfer-client-go/utils"
+)
+
+const (
+	ANCHOR        = "Anchor"
+	ANCHOR_MERKLE = "AnchorMerkle"
+	READ_ANCHORS  = "ReadAnchors"
+)
+
+// 常用锚定类型
+const (
+	KIND_SHIPPING  = "shipping"
+	KIND_ORIGIN    = "origin"
+	KIND_COLDCHAIN = "coldchain"
+)
+
+// AnchorRecord 一条锚定记录,Leaves为0表示单个文件哈希,否则Hash为默克尔根
+type AnchorRecord struct {
+	Pid    string
+	Kind   string
+	Hash   []byte
+	Uri    string
+	Leaves int
+	Height string
+	TxId   string
+}
+
+// Anchor 当前所有者或保管方为产品锚定一个文件哈希
+// pid 锚定方的伪ID
+// sk 锚定方伪ID对应私钥
+func (t *TransferChainClient) Anchor(supplyChainId, tid, pid, kind string, hash []byte, uri string, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.anchor(ANCHOR, supplyChainId, tid, pid, kind, hash, uri, nil, sk)
+}
+
+// AnchorFile 计算本地文件的哈希并锚定
+func (t *TransferChainClient) AnchorFile(supplyChainId, tid, pid, kind, filename, uri string, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return t.Anchor(supplyChainId, tid, pid, kind, sign.CalcHash(content), uri, sk)
+}
+
+// AnchorMerkle 以默克尔根批量锚定高频传感器数据,readings中每一项为一条数据
+func (t *TransferChainClient) AnchorMerkle(supplyChainId, tid, pid, kind string, readings [][]byte, uri string, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	leaves := []byte(strconv.Itoa(len(readings)))
+	return t.anchor(ANCHOR_MERKLE, supplyChainId, tid, pid, kind, merkle.Root(readings), uri, leaves, sk)
+}
+
+func (t *TransferChainClient) anchor(functionName, supplyChainId, tid, pid, kind string, hash []byte, uri string, leaves []byte, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	anchors, err := t.ReadAnchors(supplyChainId, tid)
+	if err != nil {
+		return nil, err
+	}
+	tidBytes := []byte(tid)
+	pidBytes := []byte(pid)
+	kindBytes := []byte(kind)
+	uriBytes := []byte(uri)
+	seqBytes := []byte(strconv.Itoa(len(anchors)))
+	content := utils.BytesCombine([]byte(functionName), tidBytes, pidBytes, kindBytes, hash, uriBytes, leaves, seqBytes)
+	tx := newPendingTx(supplyChainId, functionName, content, 7)
+	tx.add(0, "tid", tidBytes)
+	tx.add(1, "pid", pidBytes)
+	tx.add(2, "kind", kindBytes)
+	tx.add(3, "hash", hash)
+	tx.add(4, "uri", uriBytes)
+	tx.add(5, "leaves", leaves)
+	tx.add(6, "seq", seqBytes)
+	return t.SubmitWithKey(tx, sk)
+}
+
+// ReadAnchors 查询产品的全部锚定记录
+func (t *TransferChainClient) ReadAnchors(supplyChainId, tid string) ([]AnchorRecord, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "tid", []byte(tid))
+	result, err := t.QueryContract(supplyChainId, READ_ANCHORS, pair)
+	if err != nil {
+		return nil, err
+	}
+	records, err := decodeRecords(string(result), 7)
+	if err != nil {
+		return nil, err
+	}
+	anchors := make([]AnchorRecord, len(records))
+	for i, r := range records {
+		leaves := 0
+		if r[4] != "" {
+			leaves, err = strconv.Atoi(r[4])
+			if err != nil {
+				return nil, err
+			}
+		}
+		anchors[i] = AnchorRecord{r[0], r[1], []byte(r[2]), r[3], leaves, r[5], r[6]}
+	}
+	return anchors, nil
+}
+
+// CheckFileAnchor 检查本地文件是否与产品的某条锚定记录一致,返回全部匹配的记录
+// 单个文件记录比较文件的SHA256;默克尔记录把文件的每一行当作一条传感器数据重新计算默克尔根
+func (t *TransferChainClient) CheckFileAnchor(supplyChainId, tid, filename string) ([]AnchorRecord, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	anchors, err := t.ReadAnchors(supplyChainId, tid)
+	if err != nil {
+		return nil, err
+	}
+	fileHash := sign.CalcHash(content)
+	var readings [][]byte
+	var matched []AnchorRecord
+	for _, anchor := range anchors {
+		if anchor.Leaves == 0 {
+			if bytes.Equal(anchor.Hash, fileHash) {
+				matched = append(matched, anchor)
+			}
+			continue
+		}
+		if readings == nil {
+			readings, err = ReadLines(content)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if anchor.Leaves == len(readings) && bytes.Equal(anchor.Hash, merkle.Root(readings)) {
+			matched = append(matched, anchor)
+		}
+	}
+	return matched, nil
+}
+
+// ReadLines 将传感器数据文件按行切分,每行为一条数据
+func ReadLines(content []byte) ([][]byte, error) {
+	var lines [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := make([]byte, len(scanner.Bytes()))
+		copy(line, scanner.Bytes())
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"strconv"
+	"transfer-client-go/utils"
+)
+
+const (
+	ATTEST                 = "Attest"
+	READ_ATTESTATIONS      = "ReadAttestations"
+	SET_ATTESTATION_POLICY = "SetAttestationPolicy"
+)
+
+// 检验结果
+const (
+	ATTEST_PASS = "pass"
+	ATTEST_FAIL = "fail"
+)
+
+// Attestation 一条检验证明
+type Attestation struct {
+	Inspector   string
+	Result      string
+	Certificate []byte
+	Expire      int64
+	Height      string
+	TxId        string
+}
+
+// Attest 检验方为产品登记检验结果
+// inspector 检验方的伪ID,需由管理员登记为inspector角色
+// result 检验结果,ATTEST_PASS或ATTEST_FAIL
+// certificate 证书哈希
+// expire 证明失效的时间戳(秒),0表示长期有效
+// sk 检验方伪ID对应私钥
+func (t *TransferChainClient) Attest(supplyChainId, tid, inspector, result string, certificate []byte, expire int64, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	attestations, err := t.ReadAttestations(supplyChainId, tid)
+	if err != nil {
+		return nil, err
+	}
+	tidBytes := []byte(tid)
+	inspectorBytes := []byte(inspector)
+	resultBytes := []byte(result)
+	var expireBytes []byte
+	if expire > 0 {
+		expireBytes = []byte(strconv.FormatInt(expire, 10))
+	}
+	seqBytes := []byte(strconv.Itoa(len(attestations)))
+	content := utils.BytesCombine([]byte(ATTEST), tidBytes, inspectorBytes, resultBytes, certificate, expireBytes, seqBytes)
+	tx := newPendingTx(supplyChainId, ATTEST, content, 6)
+	tx.add(0, "tid", tidBytes)
+	tx.add(1, "inspector", inspectorBytes)
+	tx.add(2, "result", resultBytes)
+	tx.add(3, "certificate", certificate)
+	tx.add(4, "expire", expireBytes)
+	tx.add(5, "seq", seqBytes)
+	return t.SubmitWithKey(tx, sk)
+}
+
+// ReadAttestations 查询产品的全部检验证明
+func (t *TransferChainClient) ReadAttestations(supplyChainId, tid string) ([]Attestation, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "tid", []byte(tid))
+	result, err := t.QueryContract(supplyChainId, READ_ATTESTATIONS, pair)
+	if err != nil {
+		return nil, err
+	}
+	records, err := decodeRecords(string(result), 6)
+	if err != nil {
+		return nil, err
+	}
+	attestations := make([]Attestation, len(records))
+	for i, r := range records {
+		var expire int64
+		if r[3] != "" {
+			expire, err = strconv.ParseInt(r[3], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+		}
+		attestations[i] = Attestation{r[0], r[1], []byte(r[2]), expire, r[4], r[5]}
+	}
+	return attestations, nil
+}
+
+// SetAttestationPolicy 管理员设置转移前是否必须持有有效的检验证明
+func (t *TransferChainClient) SetAttestationPolicy(supplyChainId string, required bool, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	seq, err := t.readAdminSeq(supplyChainId)
+	if err != nil {
+		return nil, err
+	}
+	var requiredBytes []byte
+	if required {
+		requiredBytes = []byte("1")
+	}
+	seqBytes := []byte(strconv.Itoa(seq))
+	tx := newPendingTx(supplyChainId, SET_ATTESTATION_POLICY, utils.BytesCombine([]byte(SET_ATTESTATION_POLICY), requiredBytes, seqBytes), 2)
+	tx.add(0, "required", requiredBytes)
+	tx.add(1, "seq", seqBytes)
+	return t.SubmitWithKey(tx, adminSk)
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"math/big"
+	"transfer-client-go/blind"
+	"transfer-client-go/sign"
+	"transfer-client-go/utils"
+)
+
+// CreateNewBlindedProduct 以隐藏产品ID创建产品,返回隐藏产品ID
+// 之后上传alpha,beta以及转移时都使用隐藏产品ID作为tid
+func (t *TransferChainClient) CreateNewBlindedProduct(supplyChainId string, registry *blind.Registry, tid, pid string, adminSk *ecdsa.PrivateKey) (string, *common.TxResponse, error) {
+	blinded, tidPk, err := registry.Blind(tid)
+	if err != nil {
+		return "", nil, err
+	}
+	tidBytes := []byte(blinded)
+	pidBytes := []byte(pid)
+	tx := newPendingTx(supplyChainId, CREATE_PRODUCT, utils.BytesCombine(tidBytes, pidBytes, tidPk), 3)
+	tx.add(0, "tid", tidBytes)
+	tx.add(1, "pid", pidBytes)
+	tx.add(2, "tidPk", tidPk)
+	response, err := t.SubmitWithKey(tx, adminSk)
+	if err != nil {
+		return "", nil, err
+	}
+	return blinded, response, nil
+}
+
+// ProveTidKnowledge 为调用中registry已知的隐藏产品ID附加产品密钥签名,证明调用者知道真实产品ID
+// 需在提交之前执行,不在registry中的产品ID按普通产品ID处理
+func ProveTidKnowledge(tx *PendingTx, registry *blind.Registry) error {
+	var fields []string
+	for _, blinded := range tx.Tids {
+		tid, ok := registry.Lookup(blinded)
+		if !ok {
+			continue
+		}
+		r, s, err := sign.Sign(tx.Content, registry.TidKey(tid))
+		if err != nil {
+			return err
+		}
+		fields = append(fields, blinded, string(r), string(s))
+	}
+	if len(fields) != 0 {
+		tx.AddArg("tidSig", utils.EncodeTids(fields))
+	}
+	return nil
+}
+
+// TransferBlindedProduct 批量转移隐藏产品ID的产品,states中的tid为隐藏产品ID
+// ownerSk 产品当前所有者伪ID对应私钥,sk 接收方伪ID对应私钥
+func (t *TransferChainClient) TransferBlindedProduct(supplyChainId string, states []TxState, key *big.Int, pid string, registry *blind.Registry, ownerSk, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	tx, err := t.prepareTransfer(supplyChainId, states, crtDecryptors(key), pid, "")
+	if err != nil {
+		return nil, err
+	}
+	err = ProveTidKnowledge(tx, registry)
+	if err != nil {
+		return nil, err
+	}
+	err = AuthorizeTransfer(tx, ownerSk)
+	if err != nil {
+		return nil, err
+	}
+	return t.SubmitWithKey(tx, sk)
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/common/v2/crypto/bulletproofs"
+	"fmt"
+	"math/big"
+	"transfer-client-go/crypto"
+	"transfer-client-go/utils"
+)
+
+const (
+	READ_CIPHER       = "ReadCipher"
+	READ_CIPHER_BATCH = "ReadCipherBatch"
+	// DefaultCipherChunk 每次ReadCipherBatch查询的产品数
+	DefaultCipherChunk = 100
+)
+
+// CipherPair 产品当前的alpha与beta密文,未上传的一侧为nil
+type CipherPair struct {
+	Tid   string
+	Alpha *crypto.Gama
+	Beta  *crypto.Gama
+}
+
+// Decrypt 解密alpha与beta,返回两者之和与聚合的盲因子
+func (c *CipherPair) Decrypt(key *big.Int) (uint64, []byte, error) {
+	return c.DecryptWith(crypto.FixedKey(key))
+}
+
+// DecryptWith 按各自纪元选择成员密钥解密alpha与beta
+func (c *CipherPair) DecryptWith(keys crypto.EpochKeys) (uint64, []byte, error) {
+	return c.Open(crypto.NewCRTDecryptor(keys))
+}
+
+// Open 按alpha与beta各自的方案编号从decs中选择解密器
+func (c *CipherPair) Open(decs ...crypto.Decryptor) (uint64, []byte, error) {
+	if c.Alpha == nil || c.Beta == nil {
+		return 0, nil, fmt.Errorf("%w:%s", crypto.ErrGamaMissing, c.Tid)
+	}
+	alpha, opening1, err := c.Alpha.Open(c.Tid, true, decs...)
+	if err != nil {
+		return 0, nil, fmt.Errorf("alpha of %s:%w", c.Tid, err)
+	}
+	beta, opening2, err := c.Beta.Open(c.Tid, false, decs...)
+	if err != nil {
+		return 0, nil, fmt.Errorf("beta of %s:%w", c.Tid, err)
+	}
+	opening, err := bulletproofs.PedersenAddOpening(opening1, opening2)
+	if err != nil {
+		return 0, nil, err
+	}
+	return alpha + beta, opening, nil
+}
+
+// ReadCipher 查询单个产品的alpha与beta密文
+func (t *TransferChainClient) ReadCipher(supplyChainId, tid string) (*CipherPair, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "tid", []byte(tid))
+	result, err := t.QueryContract(supplyChainId, READ_CIPHER, pair)
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := decodeCipherPairs([]string{tid}, result)
+	if err != nil {
+		return nil, err
+	}
+	return pairs[0], nil
+}
+
+// ReadCipherBatch 批量查询产品的alpha与beta密文,结果与tids一一对应
+func (t *TransferChainClient) ReadCipherBatch(supplyChainId string, tids []string) ([]*CipherPair, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "tid", utils.EncodeTids(tids))
+	result, err := t.QueryContract(supplyChainId, READ_CIPHER_BATCH, pair)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCipherPairs(tids, result)
+}
+
+// ReadCipherChunks 按每次chunk个产品分批查询密文,chunk不大于0时使用DefaultCipherChunk
+func (t *TransferChainClient) ReadCipherChunks(supplyChainId string, tids []string, chunk int) ([]*CipherPair, error) {
+	if chunk <= 0 {
+		chunk = DefaultCipherChunk
+	}
+	pairs := make([]*CipherPair, 0, len(tids))
+	for start := 0; start < len(tids); start += chunk {
+		end := start + chunk
+		if end > len(tids) {
+			end = len(tids)
+		}
+		batch, err := t.ReadCipherBatch(supplyChainId, tids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, batch...)
+	}
+	return pairs, nil
+}
+
+// decodeCipherPairs 严格解析密文查询结果,每个gama都经过crypto.ParseGama检查
+func decodeCipherPairs(tids []string, content []byte) ([]*CipherPair, error) {
+	gamas, err := crypto.SplitGamas(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(gamas) != 2*len(tids) {
+		return nil, fmt.Errorf("%w:expect %d gamas, got %d", crypto.ErrGamaLength, 2*len(tids), len(gamas))
+	}
+	pairs := make([]*CipherPair, len(tids))
+	for i, tid := range tids {
+		pairs[i] = &CipherPair{Tid: tid}
+		pairs[i].Alpha, err = parseOptionalGama(gamas[2*i])
+		if err != nil {
+			return nil, fmt.Errorf("alpha of %s:%w", tid, err)
+		}
+		pairs[i].Beta, err = parseOptionalGama(gamas[2*i+1])
+		if err != nil {
+			return nil, fmt.Errorf("beta of %s:%w", tid, err)
+		}
+	}
+	return pairs, nil
+}
+
+func parseOptionalGama(raw []byte) (*crypto.Gama, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	return crypto.ParseGama(raw)
+}
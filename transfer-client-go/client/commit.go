@@ -0,0 +1,37 @@
+package client
+
+import (
+	"fmt"
+	"transfer-client-go/utils"
+)
+
+const READ_COMMIT_BATCH = "ReadCommitBatch"
+
+// CommitPair 产品alpha与beta的链上承诺,未上传的一侧为空
+type CommitPair struct {
+	Tid   string
+	Alpha []byte
+	Beta  []byte
+}
+
+// ReadCommitBatch 批量查询产品alpha与beta的承诺,结果与tids顺序一致
+func (t *TransferChainClient) ReadCommitBatch(supplyChainId string, tids []string) ([]CommitPair, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "tid", utils.EncodeTids(tids))
+	result, err := t.QueryContract(supplyChainId, READ_COMMIT_BATCH, pair)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := utils.DecodeStrings(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 2*len(tids) {
+		return nil, fmt.Errorf("invalid commit response")
+	}
+	commits := make([]CommitPair, len(tids))
+	for i, tid := range tids {
+		commits[i] = CommitPair{Tid: tid, Alpha: []byte(fields[2*i]), Beta: []byte(fields[2*i+1])}
+	}
+	return commits, nil
+}
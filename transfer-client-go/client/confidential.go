@@ -0,0 +1,133 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+	"transfer-client-go/blind"
+	"transfer-client-go/sign"
+	"transfer-client-go/utils"
+)
+
+const (
+	CONFIDENTIAL_TRANSFER = "ConfidentialTransfer"
+)
+
+// NewCommitOwners 接收方为每个产品派生一次性公钥,交给转出方用于ConfidentialTransfer
+func (t *TransferChainClient) NewCommitOwners(supplyChainId string, tids []string, pidSk *ecdsa.PrivateKey) ([][]byte, error) {
+	pks := make([][]byte, len(tids))
+	for i, tid := range tids {
+		history, err := t.ReadProductHistory(supplyChainId, tid)
+		if err != nil {
+			return nil, err
+		}
+		_, pk, err := blind.CommitOwnerId(&blind.OwnerKey(pidSk, tid, len(history.Owners)).PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		pks[i] = pk
+	}
+	return pks, nil
+}
+
+// FindOwnerKey 查找伪ID当前持有产品tid所用的私钥,所有者为伪ID本身时返回pidSk
+func (t *TransferChainClient) FindOwnerKey(supplyChainId, tid, pid string, pidSk *ecdsa.PrivateKey) (*ecdsa.PrivateKey, error) {
+	history, err := t.ReadProductHistory(supplyChainId, tid)
+	if err != nil {
+		return nil, err
+	}
+	if len(history.Owners) == 0 {
+		return nil, fmt.Errorf("no product:%s", tid)
+	}
+	counter := len(history.Owners) - 1
+	owner := history.Owners[counter].Owner
+	if owner == pid {
+		return pidSk, nil
+	}
+	if strings.HasPrefix(owner, blind.CommitOwnerPrefix) {
+		sk := blind.OwnerKey(pidSk, tid, counter)
+		id, _, err := blind.CommitOwnerId(&sk.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		if id == owner {
+			return sk, nil
+		}
+	}
+	return nil, fmt.Errorf("%s not owned by %s", tid, pid)
+}
+
+// PrepareConfidentialTransfer 构造保密转移调用,ownerPks为接收方通过NewCommitOwners给出的一次性公钥
+func (t *TransferChainClient) PrepareConfidentialTransfer(supplyChainId string, states []TxState, key *big.Int, ownerPks [][]byte) (*PendingTx, error) {
+	if len(ownerPks) != len(states) {
+		return nil, fmt.Errorf("owner pks must match states")
+	}
+	tids, pSecret, openings, err := t.aggregateSecrets(supplyChainId, states, crtDecryptors(key))
+	if err != nil {
+		return nil, err
+	}
+	owners := make([]string, len(ownerPks))
+	for i, pk := range ownerPks {
+		owners[i] = string(pk)
+	}
+	tidsByte := utils.EncodeTids(tids)
+	ownersByte := utils.EncodeTids(owners)
+	pSecretBytes := utils.Uint64ToBytes(pSecret)
+	tx := newPendingTx(supplyChainId, CONFIDENTIAL_TRANSFER, utils.BytesCombine([]byte(CONFIDENTIAL_TRANSFER), tidsByte, ownersByte, pSecretBytes, openings), 4)
+	tx.add(0, "tid", tidsByte)
+	tx.add(1, "owners", ownersByte)
+	tx.add(2, "pSecret", pSecretBytes)
+	tx.add(3, "opening", openings)
+	tx.Tids = tids
+	return tx, nil
+}
+
+// AcceptConfidential 接收方以每个产品的一次性私钥对转移内容签名,表示接受转入
+func (t *TransferChainClient) AcceptConfidential(tx *PendingTx, pidSk *ecdsa.PrivateKey) error {
+	sigs := make([]string, 0, 2*len(tx.Tids))
+	for _, tid := range tx.Tids {
+		history, err := t.ReadProductHistory(tx.SupplyChainId, tid)
+		if err != nil {
+			return err
+		}
+		r, s, err := sign.Sign(tx.Content, blind.OwnerKey(pidSk, tid, len(history.Owners)))
+		if err != nil {
+			return err
+		}
+		sigs = append(sigs, string(r), string(s))
+	}
+	tx.AddArg("ownerSigs", utils.EncodeTids(sigs))
+	return nil
+}
+
+// SubmitConfidential 由每个产品的当前所有者私钥签名并提交,keys与tx.Tids一一对应,提交前须先由接收方AcceptConfidential
+func (t *TransferChainClient) SubmitConfidential(tx *PendingTx, keys []*ecdsa.PrivateKey) (*common.TxResponse, error) {
+	if len(keys) != len(tx.Tids) {
+		return nil, fmt.Errorf("keys must match tids")
+	}
+	sigs := make([]string, 0, 2*len(keys))
+	for _, sk := range keys {
+		r, s, err := sign.Sign(tx.Content, sk)
+		if err != nil {
+			return nil, err
+		}
+		sigs = append(sigs, string(r), string(s))
+	}
+	tx.AddArg("sigs", utils.EncodeTids(sigs))
+	return t.invokeChecked(tx.SupplyChainId, tx.FunctionName, tx.pair)
+}
+
+// ConfidentialTransfer 把产品转移给各自独立的承诺所有者,keys为各产品当前所有者的私钥,recipientSk为接收方伪ID私钥
+func (t *TransferChainClient) ConfidentialTransfer(supplyChainId string, states []TxState, key *big.Int, ownerPks [][]byte, keys []*ecdsa.PrivateKey, recipientSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	tx, err := t.PrepareConfidentialTransfer(supplyChainId, states, key, ownerPks)
+	if err != nil {
+		return nil, err
+	}
+	err = t.AcceptConfidential(tx, recipientSk)
+	if err != nil {
+		return nil, err
+	}
+	return t.SubmitConfidential(tx, keys)
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"fmt"
+	"strconv"
+	"transfer-client-go/sign"
+	"transfer-client-go/utils"
+)
+
+const (
+	HANDOFF_CUSTODY      = "HandoffCustody"
+	READ_CUSTODY         = "ReadCustody"
+	READ_PRODUCT_HISTORY = "ReadProductHistory"
+)
+
+// OwnerRecord 一条所有权变更记录
+type OwnerRecord struct {
+	Owner  string
+	Height string
+	TxId   string
+}
+
+// CustodyRecord 一条保管权交接记录
+type CustodyRecord struct {
+	From     string
+	To       string
+	Location []byte
+	Height   string
+	TxId     string
+}
+
+// ProductHistory 产品的所有权历史与保管权交接链
+type ProductHistory struct {
+	Owners   []OwnerRecord
+	Handoffs []CustodyRecord
+}
+
+// PrepareHandoff 构造保管权交接调用,交出方与接收方分别对Content签名后通过SubmitHandoff提交
+// from 交出方的伪ID,必须是当前保管方
+// to 接收方的伪ID
+// location 交接地点的哈希,可为空
+func (t *TransferChainClient) PrepareHandoff(supplyChainId, tid, from, to string, location []byte) (*PendingTx, error) {
+	custodian, seq, err := t.ReadCustody(supplyChainId, tid)
+	if err != nil {
+		return nil, err
+	}
+	if custodian != from {
+		return nil, fmt.Errorf("%s is not current custodian of %s", from, tid)
+	}
+	tidBytes := []byte(tid)
+	fromBytes := []byte(from)
+	toBytes := []byte(to)
+	seqBytes := []byte(strconv.Itoa(seq))
+	content := utils.BytesCombine([]byte(HANDOFF_CUSTODY), tidBytes, fromBytes, toBytes, location, seqBytes)
+	tx := newPendingTx(supplyChainId, HANDOFF_CUSTODY, content, 5)
+	tx.add(0, "tid", tidBytes)
+	tx.add(1, "from", fromBytes)
+	tx.add(2, "to", toBytes)
+	tx.add(3, "location", location)
+	tx.add(4, "seq", seqBytes)
+	return tx, nil
+}
+
+// SubmitHandoff 提交交出方release与接收方receive对同一交接内容的签名
+func (t *TransferChainClient) SubmitHandoff(tx *PendingTx, release, receive sign.PartialSignature) (*common.TxResponse, error) {
+	tx.AddArg("rFrom", release.R)
+	tx.AddArg("sFrom", release.S)
+	return t.SubmitSigned(tx, receive.R, receive.S)
+}
+
+// ReadCustody 查询产品当前保管方与交接记录条数
+func (t *TransferChainClient) ReadCustody(supplyChainId, tid string) (string, int, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "tid", []byte(tid))
+	result, err := t.QueryContract(supplyChainId, READ_CUSTODY, pair)
+	if err != nil {
+		return "", 0, err
+	}
+	fields, err := utils.DecodeStrings(result)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(fields) != 2 {
+		return "", 0, fmt.Errorf("invalid custody response")
+	}
+	seq, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return "", 0, err
+	}
+	return fields[0], seq, nil
+}
+
+// ReadProductHistory 查询产品的所有权历史与保管权交接链
+func (t *TransferChainClient) ReadProductHistory(supplyChainId, tid string) (*ProductHistory, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "tid", []byte(tid))
+	result, err := t.QueryContract(supplyChainId, READ_PRODUCT_HISTORY, pair)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := utils.DecodeStrings(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid product history response")
+	}
+	owners, err := decodeRecords(fields[0], 3)
+	if err != nil {
+		return nil, err
+	}
+	handoffs, err := decodeRecords(fields[1], 5)
+	if err != nil {
+		return nil, err
+	}
+	history := new(ProductHistory)
+	for _, r := range owners {
+		history.Owners = append(history.Owners, OwnerRecord{r[0], r[1], r[2]})
+	}
+	for _, r := range handoffs {
+		history.Handoffs = append(history.Handoffs, CustodyRecord{r[0], r[1], []byte(r[2]), r[3], r[4]})
+	}
+	return history, nil
+}
+
+// ReadOwner 查询产品当前所有者,即所有权历史的最后一条
+func (t *TransferChainClient) ReadOwner(supplyChainId, tid string) (string, error) {
+	history, err := t.ReadProductHistory(supplyChainId, tid)
+	if err != nil {
+		return "", err
+	}
+	if len(history.Owners) == 0 {
+		return "", fmt.Errorf("product not exist:%s", tid)
+	}
+	return history.Owners[len(history.Owners)-1].Owner, nil
+}
+
+// decodeRecords 解析记录列表编码,每条记录需有size个字段
+func decodeRecords(content string, size int) ([][]string, error) {
+	entries, err := utils.DecodeStrings([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	records := make([][]string, len(entries))
+	for i, entry := range entries {
+		fields, err := utils.DecodeStrings([]byte(entry))
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) != size {
+			return nil, fmt.Errorf("invalid record:%d", i)
+		}
+		records[i] = fields
+	}
+	return records, nil
+}
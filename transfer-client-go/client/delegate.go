@@ -0,0 +1,121 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"fmt"
+	"strconv"
+	"transfer-client-go/sign"
+	"transfer-client-go/utils"
+)
+
+const (
+	GRANT_TRANSFER  = "GrantTransfer"
+	REVOKE_TRANSFER = "RevokeTransfer"
+	READ_GRANT      = "ReadGrant"
+)
+
+// TransferGrant 所有者授予代理方的转移权限
+type TransferGrant struct {
+	Seq    int
+	Active bool
+	Tids   []string
+	Expire uint64
+}
+
+// GrantTransfer 所有者授权代理方代为转移产品
+// owner 所有者的伪ID
+// delegate 代理方的伪ID
+// tids 授权的产品ID,为空表示所有者的全部产品
+// expire 授权失效的区块高度
+// ownerSk 所有者伪ID对应私钥
+func (t *TransferChainClient) GrantTransfer(supplyChainId, owner, delegate string, tids []string, expire uint64, ownerSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	grant, err := t.ReadGrant(supplyChainId, owner, delegate)
+	if err != nil {
+		return nil, err
+	}
+	ownerBytes := []byte(owner)
+	delegateBytes := []byte(delegate)
+	tidsByte := utils.EncodeTids(tids)
+	expireBytes := []byte(strconv.FormatUint(expire, 10))
+	seqBytes := []byte(strconv.Itoa(grant.Seq))
+	content := utils.BytesCombine([]byte(GRANT_TRANSFER), ownerBytes, delegateBytes, tidsByte, expireBytes, seqBytes)
+	r, s, err := sign.Sign(content, ownerSk)
+	if err != nil {
+		return nil, err
+	}
+	pair := utils.NewKeyValuePair(7)
+	utils.AddKeyValue(pair, 0, "owner", ownerBytes)
+	utils.AddKeyValue(pair, 1, "delegate", delegateBytes)
+	utils.AddKeyValue(pair, 2, "tid", tidsByte)
+	utils.AddKeyValue(pair, 3, "expire", expireBytes)
+	utils.AddKeyValue(pair, 4, "seq", seqBytes)
+	utils.AddKeyValue(pair, 5, "r", r)
+	utils.AddKeyValue(pair, 6, "s", s)
+	return t.invokeChecked(supplyChainId, GRANT_TRANSFER, pair)
+}
+
+// RevokeTransfer 所有者撤销代理方的转移权限
+func (t *TransferChainClient) RevokeTransfer(supplyChainId, owner, delegate string, ownerSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	grant, err := t.ReadGrant(supplyChainId, owner, delegate)
+	if err != nil {
+		return nil, err
+	}
+	ownerBytes := []byte(owner)
+	delegateBytes := []byte(delegate)
+	seqBytes := []byte(strconv.Itoa(grant.Seq))
+	r, s, err := sign.Sign(utils.BytesCombine([]byte(REVOKE_TRANSFER), ownerBytes, delegateBytes, seqBytes), ownerSk)
+	if err != nil {
+		return nil, err
+	}
+	pair := utils.NewKeyValuePair(5)
+	utils.AddKeyValue(pair, 0, "owner", ownerBytes)
+	utils.AddKeyValue(pair, 1, "delegate", delegateBytes)
+	utils.AddKeyValue(pair, 2, "seq", seqBytes)
+	utils.AddKeyValue(pair, 3, "r", r)
+	utils.AddKeyValue(pair, 4, "s", s)
+	return t.invokeChecked(supplyChainId, REVOKE_TRANSFER, pair)
+}
+
+// ReadGrant 查询owner授予delegate的转移权限
+func (t *TransferChainClient) ReadGrant(supplyChainId, owner, delegate string) (*TransferGrant, error) {
+	pair := utils.NewKeyValuePair(2)
+	utils.AddKeyValue(pair, 0, "owner", []byte(owner))
+	utils.AddKeyValue(pair, 1, "delegate", []byte(delegate))
+	result, err := t.QueryContract(supplyChainId, READ_GRANT, pair)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := utils.DecodeStrings(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid grant response")
+	}
+	grant := new(TransferGrant)
+	grant.Seq, err = strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(fields[1]) == 0 {
+		return grant, nil
+	}
+	record, err := utils.DecodeStrings([]byte(fields[1]))
+	if err != nil {
+		return nil, err
+	}
+	if len(record) != 2 {
+		return nil, fmt.Errorf("invalid grant record")
+	}
+	grant.Tids, err = utils.DecodeStrings([]byte(record[0]))
+	if err != nil {
+		return nil, err
+	}
+	grant.Expire, err = strconv.ParseUint(record[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	grant.Active = true
+	return grant, nil
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"bytes"
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"fmt"
+	"transfer-client-go/crypto"
+	"transfer-client-go/wallet"
+)
+
+// UploadAlphaDerived 上传由主秘密派生的alpha与盲因子,gama仍按enc加密,供其他授权方读取
+// 派生使用产品当前的所有权序号,见OwnerEpoch
+func (t *TransferChainClient) UploadAlphaDerived(enc crypto.Encryptor, d *wallet.SecretDeriver, supplyChainId, tid string, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	epoch, err := t.OwnerEpoch(supplyChainId, tid)
+	if err != nil {
+		return nil, err
+	}
+	secret, opening := d.Derive(tid, epoch, true)
+	return t.UploadAlphaWith(enc, secret, supplyChainId, tid, opening, sk)
+}
+
+// UploadBetaDerived 管理员上传由主秘密派生的beta与盲因子
+func (t *TransferChainClient) UploadBetaDerived(enc crypto.Encryptor, d *wallet.SecretDeriver, supplyChainId, tid string, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	epoch, err := t.OwnerEpoch(supplyChainId, tid)
+	if err != nil {
+		return nil, err
+	}
+	secret, opening := d.Derive(tid, epoch, false)
+	return t.UploadBetaWith(enc, secret, supplyChainId, tid, opening, adminSk)
+}
+
+// OwnerEpoch 查询产品的所有权序号,即所有权历史的条数,每次转移后递增
+func (t *TransferChainClient) OwnerEpoch(supplyChainId, tid string) (int, error) {
+	history, err := t.ReadProductHistory(supplyChainId, tid)
+	if err != nil {
+		return 0, err
+	}
+	return len(history.Owners), nil
+}
+
+// CheckDerived 用派生值重新计算承诺并与链上承诺比对,返回不一致的产品ID
+// alpha 为true时比对alpha,否则比对beta
+func (t *TransferChainClient) CheckDerived(supplyChainId string, tids []string, d *wallet.SecretDeriver, alpha bool) ([]string, error) {
+	commits, err := t.ReadCommitBatch(supplyChainId, tids)
+	if err != nil {
+		return nil, err
+	}
+	var mismatch []string
+	for _, c := range commits {
+		onChain := c.Beta
+		if alpha {
+			onChain = c.Alpha
+		}
+		epoch, err := t.OwnerEpoch(supplyChainId, c.Tid)
+		if err != nil {
+			return nil, err
+		}
+		commit, err := d.Commit(c.Tid, epoch, alpha)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(commit, onChain) {
+			mismatch = append(mismatch, c.Tid)
+		}
+	}
+	return mismatch, nil
+}
+
+// TransferProductDerived 转移产品,alpha与beta优先由派生器重新得到,派生器为nil的一侧解密上传交易中的gama
+// 两侧都有派生器时states只需填写产品ID,例如NewTxState(tid, "", "")
+func (t *TransferChainClient) TransferProductDerived(supplyChainId string, states []TxState, alpha, beta *wallet.SecretDeriver, decs []crypto.Decryptor, pid string, ownerSk, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	tids, pSecret, openings, err := t.aggregateDerived(supplyChainId, states, alpha, beta, decs)
+	if err != nil {
+		return nil, err
+	}
+	return t.submitTransfer(newTransferTx(supplyChainId, tids, pSecret, openings, pid, ""), ownerSk, sk)
+}
+
+// aggregateDerived 按派生器或gama得到每个产品的alpha与beta,与链上承诺比对后聚合
+// 无法解密的产品与承诺不一致的产品一起以*MismatchError返回
+func (t *TransferChainClient) aggregateDerived(supplyChainId string, states []TxState, alpha, beta *wallet.SecretDeriver, decs []crypto.Decryptor) ([]string, uint64, []byte, error) {
+	secrets := make([]ProductSecret, len(states))
+	failed := make(map[string]error)
+	for i, state := range states {
+		secrets[i].Tid = state.tid
+		var epoch int
+		var err error
+		if alpha != nil || beta != nil {
+			epoch, err = t.OwnerEpoch(supplyChainId, state.tid)
+			if err != nil {
+				return nil, 0, nil, err
+			}
+		}
+		if alpha != nil {
+			secrets[i].Alpha, secrets[i].AlphaOpening = alpha.Derive(state.tid, epoch, true)
+		} else {
+			secrets[i].Alpha, secrets[i].AlphaOpening, err = t.OpenGamaByTxId(state.txAlpha, decs...)
+			if err != nil {
+				failed[state.tid] = fmt.Errorf("alpha:%w", err)
+				continue
+			}
+		}
+		if beta != nil {
+			secrets[i].Beta, secrets[i].BetaOpening = beta.Derive(state.tid, epoch, false)
+		} else {
+			secrets[i].Beta, secrets[i].BetaOpening, err = t.OpenGamaByTxId(state.txBeta, decs...)
+			if err != nil {
+				failed[state.tid] = fmt.Errorf("beta:%w", err)
+			}
+		}
+	}
+	err := t.checkSecrets(supplyChainId, secrets, failed)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return sumSecrets(secrets)
+}
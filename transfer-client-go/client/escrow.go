@@ -0,0 +1,95 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"strconv"
+	"transfer-client-go/crypto"
+	"transfer-client-go/sign"
+	"transfer-client-go/utils"
+)
+
+const (
+	REQUEST_DEANONYMIZE = "RequestDeanonymize"
+	READ_DEANON_LOG     = "ReadDeanonLog"
+)
+
+// DeanonRecord 一条去匿名化请求记录
+type DeanonRecord struct {
+	Regulator string
+	Reason    string
+	Height    string
+	TxId      string
+}
+
+// ReadRegulatorKey 读取监管方伪ID登记的公钥,用于加密托管身份
+func (t *TransferChainClient) ReadRegulatorKey(supplyChainId, regulator string) (*ecdsa.PublicKey, error) {
+	return t.ReadPidKey(supplyChainId, regulator)
+}
+
+// EscrowIdentity 用监管方公钥加密真实身份,构造AddPid的托管记录
+func EscrowIdentity(regulator string, regulatorPk *ecdsa.PublicKey, identity []byte) ([]byte, error) {
+	ciphertext, err := crypto.EciesEncrypt(regulatorPk, identity)
+	if err != nil {
+		return nil, err
+	}
+	return utils.EncodeTids([]string{regulator, string(ciphertext)}), nil
+}
+
+// AddNewPidWithEscrow 登记伪ID并托管其真实身份,escrow由EscrowIdentity构造
+func (t *TransferChainClient) AddNewPidWithEscrow(supplyChainId string, pid, role string, pk *ecdsa.PublicKey, escrow []byte, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.addPid(supplyChainId, pid, role, pk, escrow, adminSk)
+}
+
+// Deanonymize 监管方登记去匿名化请求并解密伪ID的真实身份,请求会永久记录在链上
+// 日志是监管方自愿登记的审计记录,托管密文本身在链上公开可读,日志不能阻止不经登记的解密
+func (t *TransferChainClient) Deanonymize(supplyChainId, regulator, pid, reason string, regulatorSk *ecdsa.PrivateKey) ([]byte, *common.TxResponse, error) {
+	records, err := t.ReadDeanonLog(supplyChainId, pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	regulatorBytes := []byte(regulator)
+	pidBytes := []byte(pid)
+	reasonBytes := []byte(reason)
+	seqBytes := []byte(strconv.Itoa(len(records)))
+	content := utils.BytesCombine([]byte(REQUEST_DEANONYMIZE), regulatorBytes, pidBytes, reasonBytes, seqBytes)
+	r, s, err := sign.Sign(content, regulatorSk)
+	if err != nil {
+		return nil, nil, err
+	}
+	pair := utils.NewKeyValuePair(6)
+	utils.AddKeyValue(pair, 0, "regulator", regulatorBytes)
+	utils.AddKeyValue(pair, 1, "pid", pidBytes)
+	utils.AddKeyValue(pair, 2, "reason", reasonBytes)
+	utils.AddKeyValue(pair, 3, "seq", seqBytes)
+	utils.AddKeyValue(pair, 4, "r", r)
+	utils.AddKeyValue(pair, 5, "s", s)
+	response, err := t.invokeChecked(supplyChainId, REQUEST_DEANONYMIZE, pair)
+	if err != nil {
+		return nil, nil, err
+	}
+	identity, err := crypto.EciesDecrypt(regulatorSk, response.GetContractResult().GetResult())
+	if err != nil {
+		return nil, response, err
+	}
+	return identity, response, nil
+}
+
+// ReadDeanonLog 查询伪ID的去匿名化请求记录
+func (t *TransferChainClient) ReadDeanonLog(supplyChainId, pid string) ([]DeanonRecord, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "pid", []byte(pid))
+	result, err := t.QueryContract(supplyChainId, READ_DEANON_LOG, pair)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := decodeRecords(string(result), 4)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]DeanonRecord, len(entries))
+	for i, r := range entries {
+		records[i] = DeanonRecord{r[0], r[1], r[2], r[3]}
+	}
+	return records, nil
+}
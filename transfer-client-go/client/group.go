@@ -0,0 +1,140 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strconv"
+	"transfer-client-go/crypto"
+	"transfer-client-go/sign"
+	"transfer-client-go/utils"
+)
+
+const (
+	ADD_OWNER_GROUP  = "AddOwnerGroup"
+	READ_OWNER_GROUP = "ReadOwnerGroup"
+)
+
+// OwnerGroup 共有组,需要Threshold个成员签名才能代表组
+type OwnerGroup struct {
+	Members   []string
+	Threshold int
+}
+
+// PendingTx 参数已构造完成、等待签名的合约调用
+// 共有组的各成员分别对Content调用sign.PartialSign,收集足够的签名后通过SubmitGroup提交
+type PendingTx struct {
+	SupplyChainId string
+	FunctionName  string
+	Content       []byte
+	// Tids 调用涉及的产品ID,用于隐藏产品ID的知识证明
+	Tids []string
+	pair []*common.KeyValuePair
+}
+
+// newPendingTx 构造等待签名的调用,size为除r,s以外的参数个数
+func newPendingTx(supplyChainId, functionName string, content []byte, size int) *PendingTx {
+	return &PendingTx{
+		SupplyChainId: supplyChainId,
+		FunctionName:  functionName,
+		Content:       content,
+		pair:          utils.NewKeyValuePair(size),
+	}
+}
+
+func (tx *PendingTx) add(index int, key string, value []byte) {
+	utils.AddKeyValue(tx.pair, index, key, value)
+}
+
+// AddArg 追加一个不参与签名内容的参数
+func (tx *PendingTx) AddArg(key string, value []byte) {
+	tx.pair = append(tx.pair, &common.KeyValuePair{Key: key, Value: value})
+}
+
+// SubmitSigned 填入签名并提交调用
+func (t *TransferChainClient) SubmitSigned(tx *PendingTx, r, s []byte) (*common.TxResponse, error) {
+	pair := make([]*common.KeyValuePair, len(tx.pair), len(tx.pair)+2)
+	copy(pair, tx.pair)
+	pair = append(pair, &common.KeyValuePair{Key: "r", Value: r}, &common.KeyValuePair{Key: "s", Value: s})
+	return t.invokeChecked(tx.SupplyChainId, tx.FunctionName, pair)
+}
+
+// SubmitWithKey 使用单个伪ID的私钥签名并提交调用
+func (t *TransferChainClient) SubmitWithKey(tx *PendingTx, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	r, s, err := sign.Sign(tx.Content, sk)
+	if err != nil {
+		return nil, err
+	}
+	return t.SubmitSigned(tx, r, s)
+}
+
+// SubmitGroup 使用共有组成员的签名集合提交调用
+func (t *TransferChainClient) SubmitGroup(tx *PendingTx, parts []sign.PartialSignature) (*common.TxResponse, error) {
+	return t.SubmitSigned(tx, sign.CombineSignatures(parts), nil)
+}
+
+// PrepareUploadAlpha 构造共有产品的alpha上传调用,由共有组成员签名后提交
+func (t *TransferChainClient) PrepareUploadAlpha(miu *big.Int, secret uint64, supplyChainId, tid string, opening []byte) (*PendingTx, error) {
+	return t.prepareSecret(UPLOAD_ALPHA, crypto.NewCRTEncryptor(miu, 0), secret, supplyChainId, tid, opening)
+}
+
+// PrepareTransferProduct 构造批量转移调用,接收方或代理方为共有组时由成员签名后提交
+// 提交前需由当前所有者通过AuthorizeTransfer或AuthorizeTransferGroup授权,使用代理时由代理方授权
+// delegate 代理方的伪ID,为空表示不使用代理
+func (t *TransferChainClient) PrepareTransferProduct(supplyChainId string, states []TxState, key *big.Int, pid, delegate string) (*PendingTx, error) {
+	return t.prepareTransfer(supplyChainId, states, crtDecryptors(key), pid, delegate)
+}
+
+// AuthorizeTransfer 产品当前所有者对批量转移内容签名,需在提交之前执行
+func AuthorizeTransfer(tx *PendingTx, ownerSk *ecdsa.PrivateKey) error {
+	r, s, err := sign.Sign(tx.Content, ownerSk)
+	if err != nil {
+		return err
+	}
+	tx.AddArg("rOwner", r)
+	tx.AddArg("sOwner", s)
+	return nil
+}
+
+// AuthorizeTransferGroup 当前所有者为共有组时,以成员的签名集合授权批量转移
+func AuthorizeTransferGroup(tx *PendingTx, parts []sign.PartialSignature) {
+	tx.AddArg("rOwner", sign.CombineSignatures(parts))
+}
+
+// AddOwnerGroup 管理员登记共有组
+// gid 共有组ID
+// members 成员伪ID
+// threshold 代表共有组所需的签名数
+func (t *TransferChainClient) AddOwnerGroup(supplyChainId, gid string, members []string, threshold int, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	gidBytes := []byte(gid)
+	membersBytes := utils.EncodeTids(members)
+	thresholdBytes := []byte(strconv.Itoa(threshold))
+	tx := newPendingTx(supplyChainId, ADD_OWNER_GROUP, utils.BytesCombine([]byte(ADD_OWNER_GROUP), gidBytes, membersBytes, thresholdBytes), 3)
+	tx.add(0, "gid", gidBytes)
+	tx.add(1, "members", membersBytes)
+	tx.add(2, "threshold", thresholdBytes)
+	return t.SubmitWithKey(tx, adminSk)
+}
+
+// ReadOwnerGroup 查询共有组
+func (t *TransferChainClient) ReadOwnerGroup(supplyChainId, gid string) (*OwnerGroup, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "gid", []byte(gid))
+	result, err := t.QueryContract(supplyChainId, READ_OWNER_GROUP, pair)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := utils.DecodeStrings(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid owner group response")
+	}
+	threshold, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	return &OwnerGroup{Members: fields[1:], Threshold: threshold}, nil
+}
@@ -0,0 +1,17 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"transfer-client-go/groupkey"
+)
+
+// UploadAlphaToGroup 以组密钥加密并上传alpha,gama中记录组密钥纪元
+func (t *TransferChainClient) UploadAlphaToGroup(key *groupkey.GroupKey, secret uint64, supplyChainId, tid string, opening []byte, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.uploadSecret(UPLOAD_ALPHA, key.Encryptor(), secret, supplyChainId, tid, opening, sk)
+}
+
+// UploadBetaToGroup 以组密钥加密并上传beta,gama中记录组密钥纪元
+func (t *TransferChainClient) UploadBetaToGroup(key *groupkey.GroupKey, secret uint64, supplyChainId, tid string, opening []byte, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.uploadSecret(UPLOAD_BETA, key.Encryptor(), secret, supplyChainId, tid, opening, sk)
+}
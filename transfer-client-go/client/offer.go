@@ -0,0 +1,133 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strconv"
+	"transfer-client-go/utils"
+)
+
+const (
+	OFFER_TRANSFER  = "OfferTransfer"
+	ACCEPT_TRANSFER = "AcceptTransfer"
+	REJECT_TRANSFER = "RejectTransfer"
+	CANCEL_TRANSFER = "CancelTransfer"
+	READ_OFFER      = "ReadOffer"
+)
+
+// TransferOffer 等待接收方确认的批量转移
+type TransferOffer struct {
+	Owner        string
+	Pid          string
+	Tids         []string
+	ExpireHeight uint64
+	ExpireTime   int64
+}
+
+// OfferTransfer 当前所有者向接收方发起批量转移,返回交易的TxId即为报价ID
+// 报价不含秘密值,由接收方在AcceptTransfer时提交
+// owner 当前所有者的伪ID
+// pid 接收方的伪ID
+// expireHeight 报价失效的区块高度,0表示不限制
+// expireTime 报价失效的时间戳(秒),0表示不限制
+// ownerSk 当前所有者伪ID对应私钥
+func (t *TransferChainClient) OfferTransfer(supplyChainId string, tids []string, owner, pid string, expireHeight uint64, expireTime int64, ownerSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.SubmitWithKey(t.PrepareOfferTransfer(supplyChainId, tids, owner, pid, expireHeight, expireTime), ownerSk)
+}
+
+// PrepareOfferTransfer 构造报价调用,参数含义同OfferTransfer
+func (t *TransferChainClient) PrepareOfferTransfer(supplyChainId string, tids []string, owner, pid string, expireHeight uint64, expireTime int64) *PendingTx {
+	ownerBytes := []byte(owner)
+	tidsByte := utils.EncodeTids(tids)
+	pidBytes := []byte(pid)
+	var expireHeightBytes, expireTimeBytes []byte
+	if expireHeight > 0 {
+		expireHeightBytes = []byte(strconv.FormatUint(expireHeight, 10))
+	}
+	if expireTime > 0 {
+		expireTimeBytes = []byte(strconv.FormatInt(expireTime, 10))
+	}
+	content := utils.BytesCombine([]byte(OFFER_TRANSFER), ownerBytes, tidsByte, pidBytes, expireHeightBytes, expireTimeBytes)
+	tx := newPendingTx(supplyChainId, OFFER_TRANSFER, content, 5)
+	tx.add(0, "owner", ownerBytes)
+	tx.add(1, "tid", tidsByte)
+	tx.add(2, "pid", pidBytes)
+	tx.add(3, "expireHeight", expireHeightBytes)
+	tx.add(4, "expireTime", expireTimeBytes)
+	tx.Tids = tids
+	return tx
+}
+
+// AcceptTransfer 接收方确认报价并提交报价中产品的聚合秘密值,完成转移
+// states 报价中各产品的alpha与beta交易
+// sk 接收方伪ID对应私钥
+func (t *TransferChainClient) AcceptTransfer(supplyChainId, offerId string, states []TxState, key *big.Int, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	_, pSecret, openings, err := t.aggregateSecrets(supplyChainId, states, crtDecryptors(key))
+	if err != nil {
+		return nil, err
+	}
+	offerBytes := []byte(offerId)
+	pSecretBytes := utils.Uint64ToBytes(pSecret)
+	content := utils.BytesCombine([]byte(ACCEPT_TRANSFER), offerBytes, pSecretBytes, openings)
+	tx := newPendingTx(supplyChainId, ACCEPT_TRANSFER, content, 3)
+	tx.add(0, "offer", offerBytes)
+	tx.add(1, "pSecret", pSecretBytes)
+	tx.add(2, "opening", openings)
+	return t.SubmitWithKey(tx, sk)
+}
+
+// RejectTransfer 接收方拒绝报价
+// sk 接收方伪ID对应私钥
+func (t *TransferChainClient) RejectTransfer(supplyChainId, offerId string, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.closeOffer(REJECT_TRANSFER, supplyChainId, offerId, sk)
+}
+
+// CancelTransfer 所有者撤回报价
+// ownerSk 所有者伪ID对应私钥
+func (t *TransferChainClient) CancelTransfer(supplyChainId, offerId string, ownerSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.closeOffer(CANCEL_TRANSFER, supplyChainId, offerId, ownerSk)
+}
+
+func (t *TransferChainClient) closeOffer(functionName, supplyChainId, offerId string, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	offerBytes := []byte(offerId)
+	tx := newPendingTx(supplyChainId, functionName, utils.BytesCombine([]byte(functionName), offerBytes), 1)
+	tx.add(0, "offer", offerBytes)
+	return t.SubmitWithKey(tx, sk)
+}
+
+// ReadOffer 查询报价
+func (t *TransferChainClient) ReadOffer(supplyChainId, offerId string) (*TransferOffer, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "offer", []byte(offerId))
+	result, err := t.QueryContract(supplyChainId, READ_OFFER, pair)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := utils.DecodeStrings(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid offer response")
+	}
+	offer := &TransferOffer{Owner: fields[0], Pid: fields[1]}
+	offer.Tids, err = utils.DecodeStrings([]byte(fields[2]))
+	if err != nil {
+		return nil, err
+	}
+	if fields[3] != "" {
+		offer.ExpireHeight, err = strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if fields[4] != "" {
+		offer.ExpireTime, err = strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return offer, nil
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"strconv"
+	"transfer-client-go/sign"
+	"transfer-client-go/utils"
+)
+
+const (
+	PAUSE          = "Pause"
+	UNPAUSE        = "Unpause"
+	READ_PAUSE_LOG = "ReadPauseLog"
+)
+
+// PauseRecord 合约中的一条暂停/恢复记录
+type PauseRecord struct {
+	Action string
+	Method string
+	Height string
+	TxId   string
+}
+
+// PauseMethod 管理员暂停合约方法
+// method 需要暂停的方法名,为空表示暂停全部状态修改方法
+func (t *TransferChainClient) PauseMethod(supplyChainId, method string, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.switchPause(PAUSE, supplyChainId, method, adminSk)
+}
+
+// UnpauseMethod 管理员恢复被暂停的合约方法
+// method 需要恢复的方法名,为空表示解除全局暂停
+func (t *TransferChainClient) UnpauseMethod(supplyChainId, method string, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.switchPause(UNPAUSE, supplyChainId, method, adminSk)
+}
+
+func (t *TransferChainClient) switchPause(functionName, supplyChainId, method string, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	records, err := t.ReadPauseLog(supplyChainId)
+	if err != nil {
+		return nil, err
+	}
+	methodBytes := []byte(method)
+	seqBytes := []byte(strconv.Itoa(len(records)))
+	r, s, err := sign.Sign(utils.BytesCombine([]byte(functionName), methodBytes, seqBytes), adminSk)
+	if err != nil {
+		return nil, err
+	}
+	pair := utils.NewKeyValuePair(4)
+	utils.AddKeyValue(pair, 0, "method", methodBytes)
+	utils.AddKeyValue(pair, 1, "seq", seqBytes)
+	utils.AddKeyValue(pair, 2, "r", r)
+	utils.AddKeyValue(pair, 3, "s", s)
+	return t.invokeChecked(supplyChainId, functionName, pair)
+}
+
+// ReadPauseLog 读取合约中的全部暂停/恢复记录
+func (t *TransferChainClient) ReadPauseLog(supplyChainId string) ([]PauseRecord, error) {
+	result, err := t.QueryContract(supplyChainId, READ_PAUSE_LOG, utils.NewKeyValuePair(0))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := decodeRecords(string(result), 4)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]PauseRecord, len(entries))
+	for i, fields := range entries {
+		records[i] = PauseRecord{fields[0], fields[1], fields[2], fields[3]}
+	}
+	return records, nil
+}
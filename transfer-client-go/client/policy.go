@@ -0,0 +1,153 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"fmt"
+	"strconv"
+	"transfer-client-go/sign"
+	"transfer-client-go/utils"
+)
+
+const (
+	SET_ROLE             = "SetRole"
+	SET_TRANSFER_POLICY  = "SetTransferPolicy"
+	READ_ROLE            = "ReadRole"
+	READ_TRANSFER_POLICY = "ReadTransferPolicy"
+)
+
+// 常用角色
+const (
+	ROLE_SUPPLIER     = "supplier"
+	ROLE_MANUFACTURER = "manufacturer"
+	ROLE_DISTRIBUTOR  = "distributor"
+	ROLE_CARRIER      = "carrier"
+	ROLE_RETAILER     = "retailer"
+	ROLE_CONSUMER     = "consumer"
+	ROLE_INSPECTOR    = "inspector"
+	ROLE_REGULATOR    = "regulator"
+)
+
+// TransferPolicy 角色转移策略表
+type TransferPolicy struct {
+	Seq         int
+	Enabled     bool
+	Transitions []string
+}
+
+// Transition 构造策略表中的一项角色转移
+func Transition(from, to string) string {
+	return from + ">" + to
+}
+
+// Allow 在策略表中加入from到to的转移
+func (p *TransferPolicy) Allow(from, to string) {
+	transition := Transition(from, to)
+	for _, item := range p.Transitions {
+		if item == transition {
+			return
+		}
+	}
+	p.Transitions = append(p.Transitions, transition)
+}
+
+// Disallow 从策略表中删除from到to的转移
+func (p *TransferPolicy) Disallow(from, to string) {
+	transition := Transition(from, to)
+	var transitions []string
+	for _, item := range p.Transitions {
+		if item != transition {
+			transitions = append(transitions, item)
+		}
+	}
+	p.Transitions = transitions
+}
+
+// SetPidRole 管理员为已登记的伪ID设置角色
+func (t *TransferChainClient) SetPidRole(supplyChainId, pid, role string, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	seq, err := t.readAdminSeq(supplyChainId)
+	if err != nil {
+		return nil, err
+	}
+	pidBytes := []byte(pid)
+	roleBytes := []byte(role)
+	seqBytes := []byte(strconv.Itoa(seq))
+	r, s, err := sign.Sign(utils.BytesCombine([]byte(SET_ROLE), pidBytes, roleBytes, seqBytes), adminSk)
+	if err != nil {
+		return nil, err
+	}
+	pair := utils.NewKeyValuePair(5)
+	utils.AddKeyValue(pair, 0, "pid", pidBytes)
+	utils.AddKeyValue(pair, 1, "role", roleBytes)
+	utils.AddKeyValue(pair, 2, "seq", seqBytes)
+	utils.AddKeyValue(pair, 3, "r", r)
+	utils.AddKeyValue(pair, 4, "s", s)
+	return t.invokeChecked(supplyChainId, SET_ROLE, pair)
+}
+
+// SetTransferPolicy 管理员整体替换角色转移策略表,policy.Seq需为链上当前序号
+func (t *TransferChainClient) SetTransferPolicy(supplyChainId string, policy *TransferPolicy, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	policyBytes := utils.EncodeTids(policy.Transitions)
+	var enabledBytes []byte
+	if policy.Enabled {
+		enabledBytes = []byte("1")
+	}
+	seqBytes := []byte(strconv.Itoa(policy.Seq))
+	r, s, err := sign.Sign(utils.BytesCombine([]byte(SET_TRANSFER_POLICY), policyBytes, enabledBytes, seqBytes), adminSk)
+	if err != nil {
+		return nil, err
+	}
+	pair := utils.NewKeyValuePair(5)
+	utils.AddKeyValue(pair, 0, "policy", policyBytes)
+	utils.AddKeyValue(pair, 1, "enabled", enabledBytes)
+	utils.AddKeyValue(pair, 2, "seq", seqBytes)
+	utils.AddKeyValue(pair, 3, "r", r)
+	utils.AddKeyValue(pair, 4, "s", s)
+	return t.invokeChecked(supplyChainId, SET_TRANSFER_POLICY, pair)
+}
+
+// ReadTransferPolicy 查询角色转移策略表,修改后可直接传给SetTransferPolicy
+func (t *TransferChainClient) ReadTransferPolicy(supplyChainId string) (*TransferPolicy, error) {
+	result, err := t.QueryContract(supplyChainId, READ_TRANSFER_POLICY, utils.NewKeyValuePair(0))
+	if err != nil {
+		return nil, err
+	}
+	fields, err := utils.DecodeStrings(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid transfer policy response")
+	}
+	policy := new(TransferPolicy)
+	policy.Seq, err = strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	policy.Enabled = fields[1] == "1"
+	policy.Transitions, err = utils.DecodeStrings([]byte(fields[2]))
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// readAdminSeq 查询当前管理员操作序号
+func (t *TransferChainClient) readAdminSeq(supplyChainId string) (int, error) {
+	policy, err := t.ReadTransferPolicy(supplyChainId)
+	if err != nil {
+		return 0, err
+	}
+	return policy.Seq, nil
+}
+
+// ReadRole 查询伪ID的角色
+func (t *TransferChainClient) ReadRole(supplyChainId, pid string) (string, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "pid", []byte(pid))
+	result, err := t.QueryContract(supplyChainId, READ_ROLE, pair)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
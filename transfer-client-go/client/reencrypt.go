@@ -0,0 +1,100 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"fmt"
+	"strconv"
+	"transfer-client-go/crypto"
+	"transfer-client-go/sign"
+	"transfer-client-go/utils"
+)
+
+const (
+	GRANT_REENCRYPTION = "GrantReEncryption"
+	READ_REENCRYPTION  = "ReadReEncryption"
+)
+
+// ReEncryption 产品登记的alpha重加密密钥
+type ReEncryption struct {
+	To  string
+	Key []byte
+	X   []byte
+	// Seq 登记时产品所有权历史的条数,所有权变化后登记失效
+	Seq int
+}
+
+// UploadAlphaReEncryptable 以所有者自己的伪ID公钥按代理重加密方案上传alpha,
+// 之后可以通过GrantReEncryption把访问权交给下一任所有者
+func (t *TransferChainClient) UploadAlphaReEncryptable(secret uint64, supplyChainId, tid string, opening []byte, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.UploadAlphaWith(crypto.NewPreEncryptor(&sk.PublicKey), secret, supplyChainId, tid, opening, sk)
+}
+
+// GrantReEncryption 所有者为下一任所有者登记alpha的重加密密钥,产品转移给to时合约重加密alpha
+// to 下一任所有者的伪ID,需已登记公钥
+// sk 当前所有者伪ID对应私钥
+func (t *TransferChainClient) GrantReEncryption(supplyChainId, tid, to string, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	pair, err := t.ReadCipher(supplyChainId, tid)
+	if err != nil {
+		return nil, err
+	}
+	if pair.Alpha == nil {
+		return nil, fmt.Errorf("%w:%s", crypto.ErrGamaMissing, tid)
+	}
+	if pair.Alpha.Scheme != crypto.SchemePRE {
+		return nil, fmt.Errorf("alpha of %s is not re-encryptable", tid)
+	}
+	pk, err := t.ReadPidKey(supplyChainId, to)
+	if err != nil {
+		return nil, err
+	}
+	history, err := t.ReadProductHistory(supplyChainId, tid)
+	if err != nil {
+		return nil, err
+	}
+	rk, x, err := crypto.NewReKey(sk, pair.Alpha.KeyPart, pk)
+	if err != nil {
+		return nil, err
+	}
+	tidBytes := []byte(tid)
+	toBytes := []byte(to)
+	seqBytes := []byte(strconv.Itoa(len(history.Owners)))
+	r, s, err := sign.Sign(utils.BytesCombine([]byte(GRANT_REENCRYPTION), tidBytes, toBytes, rk, x, seqBytes), sk)
+	if err != nil {
+		return nil, err
+	}
+	p := utils.NewKeyValuePair(7)
+	utils.AddKeyValue(p, 0, "tid", tidBytes)
+	utils.AddKeyValue(p, 1, "to", toBytes)
+	utils.AddKeyValue(p, 2, "rk", rk)
+	utils.AddKeyValue(p, 3, "x", x)
+	utils.AddKeyValue(p, 4, "seq", seqBytes)
+	utils.AddKeyValue(p, 5, "r", r)
+	utils.AddKeyValue(p, 6, "s", s)
+	return t.invokeChecked(supplyChainId, GRANT_REENCRYPTION, p)
+}
+
+// ReadReEncryption 查询产品登记的重加密密钥,未登记时返回nil
+func (t *TransferChainClient) ReadReEncryption(supplyChainId, tid string) (*ReEncryption, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "tid", []byte(tid))
+	result, err := t.QueryContract(supplyChainId, READ_REENCRYPTION, pair)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	fields, err := utils.DecodeStrings(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("invalid re-encryption response")
+	}
+	seq, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return nil, err
+	}
+	return &ReEncryption{To: fields[0], Key: []byte(fields[1]), X: []byte(fields[2]), Seq: seq}, nil
+}
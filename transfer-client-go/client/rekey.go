@@ -0,0 +1,195 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"transfer-client-go/crypto"
+	"transfer-client-go/groupkey"
+)
+
+// DefaultRekeyBatch 每批读取与重传的产品数
+const DefaultRekeyBatch = 50
+
+// RekeyJob 成员移除后的重加密任务
+// 链上没有产品索引,Tids由调用方给出需要检查的产品。每批处理完成后任务写回文件,中断后载入继续,
+// 已重新上传的一侧不再能被移除的成员解密,重复处理同一批是安全的。
+type RekeyJob struct {
+	Member    string            `json:"member"`
+	Tids      []string          `json:"tids"`
+	BatchSize int               `json:"batchSize"`
+	Next      int               `json:"next"`
+	Rekeyed   []string          `json:"rekeyed"`
+	Failed    map[string]string `json:"failed"`
+}
+
+// RekeyProgress 每批完成后回调,done为已检查的产品数
+type RekeyProgress func(done, total int)
+
+func NewRekeyJob(member string, tids []string, batchSize int) *RekeyJob {
+	if batchSize <= 0 {
+		batchSize = DefaultRekeyBatch
+	}
+	return &RekeyJob{Member: member, Tids: tids, BatchSize: batchSize, Failed: make(map[string]string)}
+}
+
+// LoadRekeyJob 从文件读取未完成的任务
+func LoadRekeyJob(filename string) (*RekeyJob, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	job := new(RekeyJob)
+	err = json.Unmarshal(content, job)
+	if err != nil {
+		return nil, err
+	}
+	if job.Failed == nil {
+		job.Failed = make(map[string]string)
+	}
+	if job.BatchSize <= 0 {
+		job.BatchSize = DefaultRekeyBatch
+	}
+	return job, nil
+}
+
+// Save 将任务进度保存到文件
+func (job *RekeyJob) Save(filename string) error {
+	content, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, content, 0600)
+}
+
+// Done 判断任务是否已处理完全部产品
+func (job *RekeyJob) Done() bool {
+	return job.Next >= len(job.Tids)
+}
+
+// OwnerKeys 按所有者伪ID查找其私钥,用于重新上传该所有者产品的alpha
+type OwnerKeys func(owner string) (*ecdsa.PrivateKey, error)
+
+// Rekey 执行重加密任务
+// 用被移除成员的素数检查每个产品的alpha与beta,仍包含该成员的一侧换用新的随机秘密值与盲因子,
+// 以当前全部有效成员的组密钥加密上传,链上承诺随之更新,被移除成员已知的旧值不再能打开新承诺。
+// alpha由产品当前所有者的私钥签名上传,私钥由ownerKeys按所有者查找,beta由管理员adminSk签名上传。
+// 单个产品失败记录在Failed中并继续,再次执行时先重试Failed中的产品,filename为空时不保存进度。
+func (t *TransferChainClient) Rekey(supplyChainId string, job *RekeyJob, registry *groupkey.Registry, ownerKeys OwnerKeys, adminSk *ecdsa.PrivateKey, filename string, progress RekeyProgress) error {
+	if registry.Active(job.Member) {
+		return fmt.Errorf("member still active:%s", job.Member)
+	}
+	removed, err := registry.Keyring(job.Member)
+	if err != nil {
+		return err
+	}
+	key, err := registry.GroupKey()
+	if err != nil {
+		return err
+	}
+	retry := make([]string, 0, len(job.Failed))
+	for tid := range job.Failed {
+		retry = append(retry, tid)
+	}
+	sort.Strings(retry)
+	for start := 0; start < len(retry); start += job.BatchSize {
+		end := start + job.BatchSize
+		if end > len(retry) {
+			end = len(retry)
+		}
+		err = t.rekeyBatch(supplyChainId, job, retry[start:end], removed, key, ownerKeys, adminSk)
+		if err != nil {
+			return err
+		}
+		if filename != "" {
+			err = job.Save(filename)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for !job.Done() {
+		end := job.Next + job.BatchSize
+		if end > len(job.Tids) {
+			end = len(job.Tids)
+		}
+		err = t.rekeyBatch(supplyChainId, job, job.Tids[job.Next:end], removed, key, ownerKeys, adminSk)
+		if err != nil {
+			return err
+		}
+		job.Next = end
+		if filename != "" {
+			err = job.Save(filename)
+			if err != nil {
+				return err
+			}
+		}
+		if progress != nil {
+			progress(job.Next, len(job.Tids))
+		}
+	}
+	return nil
+}
+
+// rekeyBatch 检查并重新上传一批产品,失败的产品记录在Failed中,成功或无需处理的产品从Failed中移除
+func (t *TransferChainClient) rekeyBatch(supplyChainId string, job *RekeyJob, tids []string, removed *groupkey.Keyring, key *groupkey.GroupKey, ownerKeys OwnerKeys, adminSk *ecdsa.PrivateKey) error {
+	pairs, err := t.ReadCipherBatch(supplyChainId, tids)
+	if err != nil {
+		return err
+	}
+	for _, pair := range pairs {
+		rekeyed := false
+		if pair.Alpha != nil {
+			_, _, err := pair.Alpha.DecryptWith(removed, pair.Tid, true)
+			if err == nil {
+				err = t.rekeyAlpha(supplyChainId, pair.Tid, key, ownerKeys)
+				if err != nil {
+					job.Failed[pair.Tid] = "alpha:" + err.Error()
+					continue
+				}
+				rekeyed = true
+			}
+		}
+		if pair.Beta != nil {
+			_, _, err := pair.Beta.DecryptWith(removed, pair.Tid, false)
+			if err == nil {
+				err = t.rekeySide(UPLOAD_BETA, key, supplyChainId, pair.Tid, adminSk)
+				if err != nil {
+					job.Failed[pair.Tid] = "beta:" + err.Error()
+					continue
+				}
+				rekeyed = true
+			}
+		}
+		delete(job.Failed, pair.Tid)
+		if rekeyed {
+			job.Rekeyed = append(job.Rekeyed, pair.Tid)
+		}
+	}
+	return nil
+}
+
+// rekeyAlpha 查找产品当前所有者的私钥并重新上传alpha
+func (t *TransferChainClient) rekeyAlpha(supplyChainId, tid string, key *groupkey.GroupKey, ownerKeys OwnerKeys) error {
+	owner, err := t.ReadOwner(supplyChainId, tid)
+	if err != nil {
+		return err
+	}
+	ownerSk, err := ownerKeys(owner)
+	if err != nil {
+		return err
+	}
+	return t.rekeySide(UPLOAD_ALPHA, key, supplyChainId, tid, ownerSk)
+}
+
+// rekeySide 为产品一侧生成新的秘密值与盲因子,以组密钥加密上传
+func (t *TransferChainClient) rekeySide(functionName string, key *groupkey.GroupKey, supplyChainId, tid string, sk *ecdsa.PrivateKey) error {
+	secret, opening, err := crypto.NewSecret()
+	if err != nil {
+		return err
+	}
+	_, err = t.uploadSecret(functionName, key.Encryptor(), secret, supplyChainId, tid, opening, sk)
+	return err
+}
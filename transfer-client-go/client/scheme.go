@@ -0,0 +1,20 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"transfer-client-go/crypto"
+)
+
+// EciesEncryptorFor 读取接收方伪ID登记的公钥,构造ECIES方案的加密器
+// 以此上传的alpha或beta只有这些伪ID的私钥持有者能够解密,无需分发CRT素数
+func (t *TransferChainClient) EciesEncryptorFor(supplyChainId string, pids ...string) (crypto.Encryptor, error) {
+	recipients := make([]*ecdsa.PublicKey, 0, len(pids))
+	for _, pid := range pids {
+		pk, err := t.ReadPidKey(supplyChainId, pid)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, pk)
+	}
+	return crypto.NewEciesEncryptor(recipients...)
+}
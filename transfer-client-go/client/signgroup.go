@@ -0,0 +1,154 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"transfer-client-go/ringsig"
+	"transfer-client-go/sign"
+	"transfer-client-go/utils"
+)
+
+const (
+	ADD_SIGN_GROUP  = "AddSignGroup"
+	READ_SIGN_GROUP = "ReadSignGroup"
+)
+
+// SignGroup 签名组,任一成员都可以匿名代表组签名,Manager可以打开签名
+type SignGroup struct {
+	Manager string
+	Members []string
+}
+
+// AddSignGroup 管理员登记签名组
+func (t *TransferChainClient) AddSignGroup(supplyChainId, gid, manager string, members []string, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	gidBytes := []byte(gid)
+	managerBytes := []byte(manager)
+	membersBytes := utils.EncodeTids(members)
+	content := utils.BytesCombine([]byte(ADD_SIGN_GROUP), gidBytes, managerBytes, membersBytes)
+	r, s, err := sign.Sign(content, adminSk)
+	if err != nil {
+		return nil, err
+	}
+	pair := utils.NewKeyValuePair(5)
+	utils.AddKeyValue(pair, 0, "gid", gidBytes)
+	utils.AddKeyValue(pair, 1, "manager", managerBytes)
+	utils.AddKeyValue(pair, 2, "members", membersBytes)
+	utils.AddKeyValue(pair, 3, "r", r)
+	utils.AddKeyValue(pair, 4, "s", s)
+	return t.invokeChecked(supplyChainId, ADD_SIGN_GROUP, pair)
+}
+
+// ReadSignGroup 查询签名组
+func (t *TransferChainClient) ReadSignGroup(supplyChainId, gid string) (*SignGroup, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "gid", []byte(gid))
+	result, err := t.QueryContract(supplyChainId, READ_SIGN_GROUP, pair)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := utils.DecodeStrings(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid sign group response")
+	}
+	return &SignGroup{Manager: fields[0], Members: fields[1:]}, nil
+}
+
+// readRing 读取签名组成员公钥与管理方公钥
+func (t *TransferChainClient) readRing(supplyChainId string, group *SignGroup) ([]*ecdsa.PublicKey, *ecdsa.PublicKey, error) {
+	manager, err := t.ReadPidKey(supplyChainId, group.Manager)
+	if err != nil {
+		return nil, nil, err
+	}
+	ring := make([]*ecdsa.PublicKey, len(group.Members))
+	for i, member := range group.Members {
+		ring[i], err = t.ReadPidKey(supplyChainId, member)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return ring, manager, nil
+}
+
+// SubmitSignGroup 签名组成员以组ID匿名签名并提交调用,sk为成员自己的私钥
+func (t *TransferChainClient) SubmitSignGroup(tx *PendingTx, gid string, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	sig, err := t.signAsGroupMember(tx, gid, sk)
+	if err != nil {
+		return nil, err
+	}
+	return t.SubmitSigned(tx, sig, nil)
+}
+
+// AuthorizeTransferAsGroupMember 当前所有者为签名组时,由成员以组ID匿名授权批量转移
+func (t *TransferChainClient) AuthorizeTransferAsGroupMember(tx *PendingTx, gid string, sk *ecdsa.PrivateKey) error {
+	sig, err := t.signAsGroupMember(tx, gid, sk)
+	if err != nil {
+		return err
+	}
+	tx.AddArg("rOwner", sig)
+	return nil
+}
+
+// signAsGroupMember 签名组成员对调用内容生成可由管理方打开的环签名
+func (t *TransferChainClient) signAsGroupMember(tx *PendingTx, gid string, sk *ecdsa.PrivateKey) ([]byte, error) {
+	group, err := t.ReadSignGroup(tx.SupplyChainId, gid)
+	if err != nil {
+		return nil, err
+	}
+	ring, manager, err := t.readRing(tx.SupplyChainId, group)
+	if err != nil {
+		return nil, err
+	}
+	return ringsig.Sign(tx.Content, ring, manager, sk)
+}
+
+// TransferProductAsGroupMember 签名组成员以组ID接收产品,合约不知道是哪个成员签名
+// ownerSk 产品当前所有者伪ID对应私钥
+func (t *TransferChainClient) TransferProductAsGroupMember(supplyChainId string, states []TxState, key *big.Int, gid string, ownerSk, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	tx, err := t.prepareTransfer(supplyChainId, states, crtDecryptors(key), gid, "")
+	if err != nil {
+		return nil, err
+	}
+	err = AuthorizeTransfer(tx, ownerSk)
+	if err != nil {
+		return nil, err
+	}
+	return t.SubmitSignGroup(tx, gid, sk)
+}
+
+// OpenSignature 管理方打开对content的组签名,返回签名成员的伪ID
+func (t *TransferChainClient) OpenSignature(supplyChainId, gid string, content, sig []byte, managerSk *ecdsa.PrivateKey) (string, error) {
+	group, err := t.ReadSignGroup(supplyChainId, gid)
+	if err != nil {
+		return "", err
+	}
+	ring, _, err := t.readRing(supplyChainId, group)
+	if err != nil {
+		return "", err
+	}
+	index, err := ringsig.Open(content, ring, managerSk, sig)
+	if err != nil {
+		return "", err
+	}
+	return group.Members[index], nil
+}
+
+// OpenTransferTx 管理方打开以组ID签名的转移交易,返回签名成员的伪ID
+func (t *TransferChainClient) OpenTransferTx(supplyChainId, txId string, managerSk *ecdsa.PrivateKey) (string, error) {
+	tx, err := t.client.GetTxByTxId(txId)
+	if err != nil {
+		return "", err
+	}
+	payload := tx.GetTransaction().GetPayload()
+	if payload.GetMethod() != BATCH_TRANSFER {
+		return "", fmt.Errorf("not a transfer tx:%s", txId)
+	}
+	pid := payload.GetParameter("pid")
+	content := utils.BytesCombine(pid, payload.GetParameter("tid"), payload.GetParameter("pSecret"),
+		payload.GetParameter("opening"), payload.GetParameter("delegate"))
+	return t.OpenSignature(supplyChainId, string(pid), content, payload.GetParameter("r"), managerSk)
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"transfer-client-go/crypto"
+)
+
+// TransferProductByTid 只凭产品ID转移产品,密文从合约状态分批读取,无需保存上传交易ID
+func (t *TransferChainClient) TransferProductByTid(supplyChainId string, tids []string, key *big.Int, pid string, ownerSk, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.TransferProductFromState(supplyChainId, tids, crtDecryptors(key), nil, DefaultCipherChunk, pid, ownerSk, sk)
+}
+
+// TransferProductFromState 从合约状态读取密文并转移产品
+// fallback 可选的上传交易记录,某个产品的状态密文缺失或无法解密时改为解密对应上传交易中的gama
+// chunk 每次ReadCipherBatch读取的产品数,不大于0时使用DefaultCipherChunk
+func (t *TransferChainClient) TransferProductFromState(supplyChainId string, tids []string, decs []crypto.Decryptor, fallback []TxState, chunk int, pid string, ownerSk, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	secrets, failed, err := t.readStateSecrets(supplyChainId, tids, decs, fallback, chunk)
+	if err != nil {
+		return nil, err
+	}
+	err = t.checkSecrets(supplyChainId, secrets, failed)
+	if err != nil {
+		return nil, err
+	}
+	tids, pSecret, openings, err := sumSecrets(secrets)
+	if err != nil {
+		return nil, err
+	}
+	return t.submitTransfer(newTransferTx(supplyChainId, tids, pSecret, openings, pid, ""), ownerSk, sk)
+}
+
+// readStateSecrets 分批读取并解密每个产品的alpha与beta,同时返回解密失败的产品与原因
+func (t *TransferChainClient) readStateSecrets(supplyChainId string, tids []string, decs []crypto.Decryptor, fallback []TxState, chunk int) ([]ProductSecret, map[string]error, error) {
+	pairs, err := t.ReadCipherChunks(supplyChainId, tids, chunk)
+	if err != nil {
+		return nil, nil, err
+	}
+	states := make(map[string]TxState, len(fallback))
+	for _, state := range fallback {
+		states[state.tid] = state
+	}
+	secrets := make([]ProductSecret, len(pairs))
+	failed := make(map[string]error)
+	for i, pair := range pairs {
+		state, ok := states[pair.Tid]
+		secrets[i].Tid = pair.Tid
+		secrets[i].Alpha, secrets[i].AlphaOpening, err = t.openSide(pair.Alpha, pair.Tid, true, decs, state.txAlpha, ok)
+		if err != nil {
+			failed[pair.Tid] = fmt.Errorf("alpha:%w", err)
+			continue
+		}
+		secrets[i].Beta, secrets[i].BetaOpening, err = t.openSide(pair.Beta, pair.Tid, false, decs, state.txBeta, ok)
+		if err != nil {
+			failed[pair.Tid] = fmt.Errorf("beta:%w", err)
+		}
+	}
+	return secrets, failed, nil
+}
+
+// openSide 解密状态中的gama,失败且存在上传交易记录时改为解密上传交易中的gama
+func (t *TransferChainClient) openSide(gama *crypto.Gama, tid string, alpha bool, decs []crypto.Decryptor, txId string, hasTx bool) (uint64, []byte, error) {
+	err := crypto.ErrGamaMissing
+	if gama != nil {
+		var secret uint64
+		var opening []byte
+		secret, opening, err = gama.Open(tid, alpha, decs...)
+		if err == nil {
+			return secret, opening, nil
+		}
+	}
+	if !hasTx || txId == "" {
+		return 0, nil, err
+	}
+	return t.OpenGamaByTxId(txId, decs...)
+}
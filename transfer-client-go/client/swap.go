@@ -0,0 +1,143 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strconv"
+	"transfer-client-go/utils"
+)
+
+const (
+	PROPOSE_SWAP  = "ProposeSwap"
+	COMPLETE_SWAP = "CompleteSwap"
+	CANCEL_SWAP   = "CancelSwap"
+	READ_SWAP     = "ReadSwap"
+)
+
+// ProductSwap 等待对方完成的产品互换
+type ProductSwap struct {
+	Owner        string
+	Tids         []string
+	Counterparty string
+	CounterTids  []string
+	ExpireHeight uint64
+}
+
+// ProposeSwap 发起方承诺自己的产品批次,返回交易的TxId即为互换ID
+// 发起交易不含秘密值,发起方通过SwapSecret计算后线下交给对方,在CompleteSwap中提交
+// tids 发起方换出的产品ID
+// owner 发起方的伪ID
+// counterparty 对方的伪ID
+// counterTids 对方需要换出的产品ID
+// expireHeight 互换失效的区块高度,0表示不限制
+// ownerSk 发起方伪ID对应私钥
+func (t *TransferChainClient) ProposeSwap(supplyChainId string, tids []string, owner, counterparty string, counterTids []string, expireHeight uint64, ownerSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.SubmitWithKey(t.PrepareProposeSwap(supplyChainId, tids, owner, counterparty, counterTids, expireHeight), ownerSk)
+}
+
+// PrepareProposeSwap 构造发起互换调用,参数含义同ProposeSwap
+func (t *TransferChainClient) PrepareProposeSwap(supplyChainId string, tids []string, owner, counterparty string, counterTids []string, expireHeight uint64) *PendingTx {
+	ownerBytes := []byte(owner)
+	tidsByte := utils.EncodeTids(tids)
+	counterpartyBytes := []byte(counterparty)
+	counterTidsByte := utils.EncodeTids(counterTids)
+	var expireHeightBytes []byte
+	if expireHeight > 0 {
+		expireHeightBytes = []byte(strconv.FormatUint(expireHeight, 10))
+	}
+	content := utils.BytesCombine([]byte(PROPOSE_SWAP), ownerBytes, tidsByte, counterpartyBytes, counterTidsByte, expireHeightBytes)
+	tx := newPendingTx(supplyChainId, PROPOSE_SWAP, content, 5)
+	tx.add(0, "owner", ownerBytes)
+	tx.add(1, "tid", tidsByte)
+	tx.add(2, "counterparty", counterpartyBytes)
+	tx.add(3, "counterTid", counterTidsByte)
+	tx.add(4, "expireHeight", expireHeightBytes)
+	tx.Tids = tids
+	return tx
+}
+
+// SwapSecret 发起方计算自己批次的聚合秘密值与盲因子,线下交给对方用于CompleteSwap
+func (t *TransferChainClient) SwapSecret(supplyChainId string, states []TxState, key *big.Int) ([]byte, []byte, error) {
+	_, pSecret, openings, err := t.aggregateSecrets(supplyChainId, states, crtDecryptors(key))
+	if err != nil {
+		return nil, nil, err
+	}
+	return utils.Uint64ToBytes(pSecret), openings, nil
+}
+
+// CompleteSwap 对方承诺自己的产品批次并完成互换
+// states 对方产品批次,需与发起时指定的产品ID一致
+// ownerSecret,ownerOpening 发起方通过SwapSecret提供的聚合秘密值与盲因子
+// sk 对方伪ID对应私钥
+func (t *TransferChainClient) CompleteSwap(supplyChainId, swapId string, states []TxState, key *big.Int, ownerSecret, ownerOpening []byte, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	tx, err := t.PrepareCompleteSwap(supplyChainId, swapId, states, key, ownerSecret, ownerOpening)
+	if err != nil {
+		return nil, err
+	}
+	return t.SubmitWithKey(tx, sk)
+}
+
+// PrepareCompleteSwap 构造完成互换调用,参数含义同CompleteSwap
+func (t *TransferChainClient) PrepareCompleteSwap(supplyChainId, swapId string, states []TxState, key *big.Int, ownerSecret, ownerOpening []byte) (*PendingTx, error) {
+	tids, pSecret, openings, err := t.aggregateSecrets(supplyChainId, states, crtDecryptors(key))
+	if err != nil {
+		return nil, err
+	}
+	swapBytes := []byte(swapId)
+	pSecretBytes := utils.Uint64ToBytes(pSecret)
+	content := utils.BytesCombine([]byte(COMPLETE_SWAP), swapBytes, pSecretBytes, openings, ownerSecret, ownerOpening)
+	tx := newPendingTx(supplyChainId, COMPLETE_SWAP, content, 5)
+	tx.add(0, "swap", swapBytes)
+	tx.add(1, "pSecret", pSecretBytes)
+	tx.add(2, "opening", openings)
+	tx.add(3, "ownerSecret", ownerSecret)
+	tx.add(4, "ownerOpening", ownerOpening)
+	tx.Tids = tids
+	return tx, nil
+}
+
+// CancelSwap 任意一方取消尚未完成的互换
+// pid 取消方的伪ID
+// sk 取消方伪ID对应私钥
+func (t *TransferChainClient) CancelSwap(supplyChainId, swapId, pid string, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	swapBytes := []byte(swapId)
+	tx := newPendingTx(supplyChainId, CANCEL_SWAP, utils.BytesCombine([]byte(CANCEL_SWAP), swapBytes), 2)
+	tx.add(0, "swap", swapBytes)
+	tx.add(1, "pid", []byte(pid))
+	return t.SubmitWithKey(tx, sk)
+}
+
+// ReadSwap 查询互换
+func (t *TransferChainClient) ReadSwap(supplyChainId, swapId string) (*ProductSwap, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "swap", []byte(swapId))
+	result, err := t.QueryContract(supplyChainId, READ_SWAP, pair)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := utils.DecodeStrings(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid swap response")
+	}
+	swap := &ProductSwap{Owner: fields[0], Counterparty: fields[2]}
+	swap.Tids, err = utils.DecodeStrings([]byte(fields[1]))
+	if err != nil {
+		return nil, err
+	}
+	swap.CounterTids, err = utils.DecodeStrings([]byte(fields[3]))
+	if err != nil {
+		return nil, err
+	}
+	if fields[4] != "" {
+		swap.ExpireHeight, err = strconv.ParseUint(fields[4], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return swap, nil
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"math/big"
+	"transfer-client-go/crypto"
+	"transfer-client-go/threshold"
+)
+
+// UploadBetaThreshold 以门限配置中的管理方公钥封装对称密钥并上传beta,解密需要保管人的解密份额
+func (t *TransferChainClient) UploadBetaThreshold(cfg *threshold.Config, secret uint64, supplyChainId, tid string, opening []byte, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	enc, err := cfg.Encryptor()
+	if err != nil {
+		return nil, err
+	}
+	return t.UploadBetaWith(enc, secret, supplyChainId, tid, opening, adminSk)
+}
+
+// BetaRequests 读取各产品beta上传交易中的密钥部分,返回需要保管人解密的请求
+// 非门限方案上传的beta不需要保管人参与,不包含在结果中
+func (t *TransferChainClient) BetaRequests(states []TxState) ([]threshold.Request, error) {
+	var requests []threshold.Request
+	for _, state := range states {
+		raw, tid, _, err := t.readGamaTx(state.txBeta)
+		if err != nil {
+			return nil, err
+		}
+		gama, err := crypto.ParseGama(raw)
+		if err != nil {
+			return nil, err
+		}
+		if gama.Scheme == crypto.SchemeThreshold {
+			requests = append(requests, threshold.Request{Tid: tid, KeyPart: gama.KeyPart})
+		}
+	}
+	return requests, nil
+}
+
+// TransferProductThreshold 转移产品,alpha用成员密钥解密,门限方案的beta用保管人的解密份额合成
+// shares 保管人对BetaRequests给出的解密份额
+func (t *TransferChainClient) TransferProductThreshold(supplyChainId string, states []TxState, key *big.Int, cfg *threshold.Config, shares []*threshold.DecShare, pid string, ownerSk, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	decs := append(crtDecryptors(key), cfg.Decryptor(shares))
+	return t.TransferProductWith(supplyChainId, states, decs, pid, ownerSk, sk)
+}
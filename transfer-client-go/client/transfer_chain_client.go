@@ -1,7 +1,6 @@
 package client
 
 import (
-	"chainmaker.org/chainmaker/common/v2/crypto/bulletproofs"
 	"chainmaker.org/chainmaker/pb-go/v2/common"
 	sdk "chainmaker.org/chainmaker/sdk-go/v2"
 	"crypto/ecdsa"
@@ -98,105 +97,209 @@ func (t *TransferChainClient) CreateNewProduct(supplyChainId, tid, pid string, a
 //pid 伪ID
 //pk 伪ID对应公钥
 func (t *TransferChainClient) AddNewPid(supplyChainId string, pid string, pk *ecdsa.PublicKey, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
-	p := utils.NewKeyValuePair(4)
+	return t.AddNewPidWithRole(supplyChainId, pid, "", pk, adminSk)
+}
+
+//AddNewPidWithRole 登记伪ID并同时设置其角色
+//role 伪ID的角色,为空表示不设置
+func (t *TransferChainClient) AddNewPidWithRole(supplyChainId string, pid, role string, pk *ecdsa.PublicKey, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.addPid(supplyChainId, pid, role, pk, nil, adminSk)
+}
+
+func (t *TransferChainClient) addPid(supplyChainId string, pid, role string, pk *ecdsa.PublicKey, escrow []byte, adminSk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	p := utils.NewKeyValuePair(6)
 	pkBytes, err := x509.MarshalPKIXPublicKey(pk)
 	if err != nil {
 		return nil, err
 	}
 	pidBytes := []byte(pid)
-	r, s, err := sign.Sign(utils.BytesCombine(pidBytes, pkBytes), adminSk)
+	roleBytes := []byte(role)
+	r, s, err := sign.Sign(utils.BytesCombine(pidBytes, pkBytes, roleBytes, escrow), adminSk)
 	if err != nil {
 		return nil, err
 	}
 	utils.AddKeyValue(p, 0, "pid", pidBytes)
 	utils.AddKeyValue(p, 1, "pk", pkBytes)
-	utils.AddKeyValue(p, 2, "r", r)
-	utils.AddKeyValue(p, 3, "s", s)
-	response, err := t.InvokeContract(supplyChainId, ADD_PID, p)
-	if err != nil {
-		return nil, nil
-	}
-	return response, nil
+	utils.AddKeyValue(p, 2, "role", roleBytes)
+	utils.AddKeyValue(p, 3, "escrow", escrow)
+	utils.AddKeyValue(p, 4, "r", r)
+	utils.AddKeyValue(p, 5, "s", s)
+	return t.invokeChecked(supplyChainId, ADD_PID, p)
 }
 
 func (t *TransferChainClient) UploadAlpha(miu *big.Int, secret uint64, supplyChainId, tid string, opening []byte, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
-	return t.uploadSecret(UPLOAD_ALPHA, miu, secret, supplyChainId, tid, opening, sk)
+	return t.uploadSecret(UPLOAD_ALPHA, crypto.NewCRTEncryptor(miu, 0), secret, supplyChainId, tid, opening, sk)
 }
 
 func (t *TransferChainClient) UploadBeta(miu *big.Int, secret uint64, supplyChainId, tid string, opening []byte, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
-	return t.uploadSecret(UPLOAD_BETA, miu, secret, supplyChainId, tid, opening, sk)
+	return t.uploadSecret(UPLOAD_BETA, crypto.NewCRTEncryptor(miu, 0), secret, supplyChainId, tid, opening, sk)
 }
 
-func (t *TransferChainClient) uploadSecret(functionName string, miu *big.Int, secret uint64, supplyChainId, tid string, opening []byte, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
-	pair := utils.NewKeyValuePair(5)
-	tidBytes := []byte(tid)
-	gama, commit, err := crypto.Encrypt(miu, secret, opening)
+//UploadAlphaWith 以enc指定的方案封装对称密钥并上传alpha
+func (t *TransferChainClient) UploadAlphaWith(enc crypto.Encryptor, secret uint64, supplyChainId, tid string, opening []byte, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.uploadSecret(UPLOAD_ALPHA, enc, secret, supplyChainId, tid, opening, sk)
+}
+
+//UploadBetaWith 以enc指定的方案封装对称密钥并上传beta
+func (t *TransferChainClient) UploadBetaWith(enc crypto.Encryptor, secret uint64, supplyChainId, tid string, opening []byte, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.uploadSecret(UPLOAD_BETA, enc, secret, supplyChainId, tid, opening, sk)
+}
+
+func (t *TransferChainClient) uploadSecret(functionName string, enc crypto.Encryptor, secret uint64, supplyChainId, tid string, opening []byte, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	tx, err := t.prepareSecret(functionName, enc, secret, supplyChainId, tid, opening)
 	if err != nil {
 		return nil, err
 	}
-	r, s, err := sign.Sign(utils.BytesCombine(tidBytes, gama, commit), sk)
+	return t.SubmitWithKey(tx, sk)
+}
+
+func (t *TransferChainClient) prepareSecret(functionName string, enc crypto.Encryptor, secret uint64, supplyChainId, tid string, opening []byte) (*PendingTx, error) {
+	tidBytes := []byte(tid)
+	gama, commit, err := crypto.SealGama(enc, tid, functionName == UPLOAD_ALPHA, secret, opening)
 	if err != nil {
 		return nil, err
 	}
-	utils.AddKeyValue(pair, 0, "tid", tidBytes)
-	utils.AddKeyValue(pair, 1, "gama", gama)
-	utils.AddKeyValue(pair, 2, "commit", commit)
-	utils.AddKeyValue(pair, 3, "r", r)
-	utils.AddKeyValue(pair, 4, "s", s)
-	response, err := t.InvokeContract(supplyChainId, functionName, pair)
+	proof, err := crypto.ProveSecretRange(secret, opening)
 	if err != nil {
 		return nil, err
 	}
-	if response.GetCode() != 0 {
-		return nil, fmt.Errorf("tx execute fail:" + response.GetMessage())
-	}
-	return response, nil
+	tx := newPendingTx(supplyChainId, functionName, utils.BytesCombine(tidBytes, gama, commit), 4)
+	tx.add(0, "tid", tidBytes)
+	tx.add(1, "gama", gama)
+	tx.add(2, "commit", commit)
+	tx.add(3, "proof", proof)
+	return tx, nil
 }
 
 func (t *TransferChainClient) ReadGamaByTxId(txId string, s *big.Int) (uint64, []byte, error) {
-	tx, err := t.client.GetTxByTxId(txId)
+	return t.ReadGamaByTxIdWith(txId, crypto.FixedKey(s))
+}
+
+// ReadGamaByTxIdWith 读取上传交易中的gama,按其纪元选择成员密钥解密
+func (t *TransferChainClient) ReadGamaByTxIdWith(txId string, keys crypto.EpochKeys) (uint64, []byte, error) {
+	return t.OpenGamaByTxId(txId, crypto.NewCRTDecryptor(keys))
+}
+
+// OpenGamaByTxId 读取上传交易中的gama,按其方案编号从decs中选择解密器
+func (t *TransferChainClient) OpenGamaByTxId(txId string, decs ...crypto.Decryptor) (uint64, []byte, error) {
+	gama, tid, alpha, err := t.readGamaTx(txId)
 	if err != nil {
 		return 0, nil, err
 	}
-	gama := tx.GetTransaction().GetPayload().GetParameter("gama")
-	return crypto.Decrypt(s, gama)
+	return crypto.OpenGama(gama, tid, alpha, decs...)
+}
+
+// readGamaTx 读取上传交易中的gama,返回gama,产品ID与是否为alpha
+func (t *TransferChainClient) readGamaTx(txId string) ([]byte, string, bool, error) {
+	tx, err := t.client.GetTxByTxId(txId)
+	if err != nil {
+		return nil, "", false, err
+	}
+	payload := tx.GetTransaction().GetPayload()
+	return payload.GetParameter("gama"), string(payload.GetParameter("tid")), payload.GetMethod() == UPLOAD_ALPHA, nil
+}
+
+//TransferProduct 转移产品,当前所有者与接收方分别对同一内容签名
+//ownerSk 产品当前所有者伪ID对应私钥
+//sk 接收方伪ID对应私钥
+func (t *TransferChainClient) TransferProduct(supplyChainId string, states []TxState, key *big.Int, pid string, ownerSk, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.transferProduct(supplyChainId, states, crtDecryptors(key), pid, "", ownerSk, sk)
+}
+
+//TransferProductWith 转移产品,各产品的alpha与beta按其gama头部的方案编号从decs中选择解密器
+func (t *TransferChainClient) TransferProductWith(supplyChainId string, states []TxState, decs []crypto.Decryptor, pid string, ownerSk, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.transferProduct(supplyChainId, states, decs, pid, "", ownerSk, sk)
+}
+
+//TransferProductAsDelegate 代理方凭所有者授权代替所有者转移产品,接收方仍需签名
+//delegate 代理方的伪ID
+//delegateSk 代理方伪ID对应私钥
+//sk 接收方伪ID对应私钥
+func (t *TransferChainClient) TransferProductAsDelegate(supplyChainId string, states []TxState, key *big.Int, pid, delegate string, delegateSk, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	return t.transferProduct(supplyChainId, states, crtDecryptors(key), pid, delegate, delegateSk, sk)
+}
+
+// transferProduct ownerSk 为当前所有者私钥,使用代理时为代理方私钥
+func (t *TransferChainClient) transferProduct(supplyChainId string, states []TxState, decs []crypto.Decryptor, pid, delegate string, ownerSk, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	tx, err := t.prepareTransfer(supplyChainId, states, decs, pid, delegate)
+	if err != nil {
+		return nil, err
+	}
+	return t.submitTransfer(tx, ownerSk, sk)
+}
+
+// submitTransfer 由当前所有者(或代理方)授权后以接收方私钥签名提交批量转移
+func (t *TransferChainClient) submitTransfer(tx *PendingTx, ownerSk, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
+	err := AuthorizeTransfer(tx, ownerSk)
+	if err != nil {
+		return nil, err
+	}
+	return t.SubmitWithKey(tx, sk)
 }
 
-func (t *TransferChainClient) TransferProduct(supplyChainId string, states []TxState, key *big.Int, pid string, sk *ecdsa.PrivateKey) (*common.TxResponse, error) {
-	var pSecret uint64 = 0
-	openings := make([]byte, 32)
-	var tids []string
+func (t *TransferChainClient) prepareTransfer(supplyChainId string, states []TxState, decs []crypto.Decryptor, pid, delegate string) (*PendingTx, error) {
+	tids, pSecret, openings, err := t.aggregateSecrets(supplyChainId, states, decs)
+	if err != nil {
+		return nil, err
+	}
+	return newTransferTx(supplyChainId, tids, pSecret, openings, pid, delegate), nil
+}
+
+// newTransferTx 由聚合的秘密值与盲因子构造批量转移调用
+func newTransferTx(supplyChainId string, tids []string, pSecret uint64, openings []byte, pid, delegate string) *PendingTx {
+	tidsByte := utils.EncodeTids(tids)
+	pidBytes := []byte(pid)
+	delegateBytes := []byte(delegate)
+	pSecretBytes := utils.Uint64ToBytes(pSecret)
+	tx := newPendingTx(supplyChainId, BATCH_TRANSFER, utils.BytesCombine(pidBytes, tidsByte, pSecretBytes, openings, delegateBytes), 5)
+	tx.add(0, "tid", tidsByte)
+	tx.add(1, "pid", pidBytes)
+	tx.add(2, "pSecret", pSecretBytes)
+	tx.add(3, "opening", openings)
+	tx.add(4, "delegate", delegateBytes)
+	tx.Tids = tids
+	return tx
+}
+
+// aggregateSecrets 解密每个产品的alpha与beta并与链上承诺比对,返回产品ID列表,聚合的秘密值与聚合的盲因子
+// 存在不一致或无法解密的产品时返回*MismatchError,不发送任何交易
+func (t *TransferChainClient) aggregateSecrets(supplyChainId string, states []TxState, decs []crypto.Decryptor) ([]string, uint64, []byte, error) {
+	secrets := make([]ProductSecret, len(states))
+	failed := make(map[string]error)
 	for i := range states {
 		state := states[i]
-		tids = append(tids, state.tid)
-		alpha, opening1, err := t.ReadGamaByTxId(state.txAlpha, key)
+		secrets[i].Tid = state.tid
+		alpha, opening1, err := t.OpenGamaByTxId(state.txAlpha, decs...)
 		if err != nil {
-			return nil, err
+			failed[state.tid] = fmt.Errorf("alpha:%w", err)
+			continue
 		}
-		beta, opening2, err := t.ReadGamaByTxId(state.txAlpha, key)
+		beta, opening2, err := t.OpenGamaByTxId(state.txBeta, decs...)
 		if err != nil {
-			return nil, err
+			failed[state.tid] = fmt.Errorf("beta:%w", err)
+			continue
 		}
-		pSecret = pSecret + alpha + beta
-		tpOpening, _ := bulletproofs.PedersenAddOpening(opening1, opening2)
-		tpScOpening, _ := bulletproofs.PedersenAddOpening(tpOpening, openings)
-		openings = tpScOpening
+		secrets[i] = ProductSecret{state.tid, alpha, opening1, beta, opening2}
 	}
-	tidsByte := utils.EncodeTids(tids)
-	pidBytes := []byte(pid)
-	pair := utils.NewKeyValuePair(6)
-	pSecretBytes := utils.Uint64ToBytes(pSecret)
-	utils.AddKeyValue(pair, 0, "tid", tidsByte)
-	utils.AddKeyValue(pair, 1, "pid", pidBytes)
-	utils.AddKeyValue(pair, 2, "pSecret", pSecretBytes)
-	utils.AddKeyValue(pair, 3, "opening", openings)
-	r, s, err := sign.Sign(utils.BytesCombine(pidBytes, tidsByte, pSecretBytes, openings), sk)
+	err := t.checkSecrets(supplyChainId, secrets, failed)
 	if err != nil {
-		return nil, err
+		return nil, 0, nil, err
 	}
-	utils.AddKeyValue(pair, 4, "r", r)
-	utils.AddKeyValue(pair, 5, "s", s)
-	response, err := t.InvokeContract(supplyChainId, BATCH_TRANSFER, pair)
+	return sumSecrets(secrets)
+}
+
+// crtDecryptors 把单个成员密钥包装为CRT方案的解密器列表
+func crtDecryptors(key *big.Int) []crypto.Decryptor {
+	return []crypto.Decryptor{crypto.NewCRTDecryptor(crypto.FixedKey(key))}
+}
+
+func (t *TransferChainClient) InvokeContract(supplyChainId, functionName string, p []*common.KeyValuePair) (*common.TxResponse, error) {
+	return t.client.InvokeContract("SC"+supplyChainId, functionName, "", p, 10000, true)
+}
+
+// invokeChecked 调用合约并在交易执行失败时返回错误
+func (t *TransferChainClient) invokeChecked(supplyChainId, functionName string, p []*common.KeyValuePair) (*common.TxResponse, error) {
+	response, err := t.InvokeContract(supplyChainId, functionName, p)
 	if err != nil {
 		return nil, err
 	}
@@ -206,8 +309,16 @@ func (t *TransferChainClient) TransferProduct(supplyChainId string, states []TxS
 	return response, nil
 }
 
-func (t *TransferChainClient) InvokeContract(supplyChainId, functionName string, p []*common.KeyValuePair) (*common.TxResponse, error) {
-	return t.client.InvokeContract("SC"+supplyChainId, functionName, "", p, 10000, true)
+//QueryContract 调用合约的只读方法,返回合约执行结果
+func (t *TransferChainClient) QueryContract(supplyChainId, functionName string, p []*common.KeyValuePair) ([]byte, error) {
+	response, err := t.client.QueryContract("SC"+supplyChainId, functionName, p, 10000)
+	if err != nil {
+		return nil, err
+	}
+	if response.GetCode() != 0 {
+		return nil, fmt.Errorf("query fail:" + response.GetMessage())
+	}
+	return response.GetContractResult().GetResult(), nil
 }
 
 func NewTxState(tid, alphaTx, betaTx string) TxState {
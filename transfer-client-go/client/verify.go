@@ -0,0 +1,122 @@
+package client
+
+import (
+	"bytes"
+	"chainmaker.org/chainmaker/common/v2/crypto/bulletproofs"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrSecretMismatch 解密或派生得到的秘密值与链上承诺不一致
+var ErrSecretMismatch = errors.New("secret not match commit")
+
+// MismatchError 列出秘密值与链上承诺不一致的产品ID,以及alpha或beta无法解密的产品与原因
+type MismatchError struct {
+	Tids   []string
+	Failed map[string]error
+}
+
+func (e *MismatchError) Error() string {
+	var parts []string
+	if len(e.Tids) != 0 {
+		parts = append(parts, ErrSecretMismatch.Error()+":"+strings.Join(e.Tids, ","))
+	}
+	failed := make([]string, 0, len(e.Failed))
+	for tid := range e.Failed {
+		failed = append(failed, tid)
+	}
+	sort.Strings(failed)
+	for _, tid := range failed {
+		parts = append(parts, "decrypt fail:"+tid+":"+e.Failed[tid].Error())
+	}
+	return strings.Join(parts, ";")
+}
+
+// Unwrap 存在承诺不一致的产品时返回ErrSecretMismatch,只有解密失败时返回nil
+func (e *MismatchError) Unwrap() error {
+	if len(e.Tids) == 0 {
+		return nil
+	}
+	return ErrSecretMismatch
+}
+
+// ProductSecret 产品的alpha与beta及各自的盲因子
+type ProductSecret struct {
+	Tid          string
+	Alpha        uint64
+	AlphaOpening []byte
+	Beta         uint64
+	BetaOpening  []byte
+}
+
+// VerifySecrets 重新计算每个产品alpha与beta的承诺并与链上承诺比对,返回不一致的产品ID
+func (t *TransferChainClient) VerifySecrets(supplyChainId string, secrets []ProductSecret) ([]string, error) {
+	tids := make([]string, len(secrets))
+	for i := range secrets {
+		tids[i] = secrets[i].Tid
+	}
+	commits, err := t.ReadCommitBatch(supplyChainId, tids)
+	if err != nil {
+		return nil, err
+	}
+	var mismatch []string
+	for i, s := range secrets {
+		alpha, err := bulletproofs.PedersenCommitSpecificOpening(s.Alpha, s.AlphaOpening)
+		if err != nil {
+			return nil, fmt.Errorf("alpha of %s:%w", s.Tid, err)
+		}
+		beta, err := bulletproofs.PedersenCommitSpecificOpening(s.Beta, s.BetaOpening)
+		if err != nil {
+			return nil, fmt.Errorf("beta of %s:%w", s.Tid, err)
+		}
+		if !bytes.Equal(alpha, commits[i].Alpha) || !bytes.Equal(beta, commits[i].Beta) {
+			mismatch = append(mismatch, s.Tid)
+		}
+	}
+	return mismatch, nil
+}
+
+// checkSecrets 发送转移前校验秘密值,failed为解密失败的产品,这些产品不参与承诺比对
+// 存在不一致或解密失败的产品时返回*MismatchError
+func (t *TransferChainClient) checkSecrets(supplyChainId string, secrets []ProductSecret, failed map[string]error) error {
+	opened := make([]ProductSecret, 0, len(secrets))
+	for _, s := range secrets {
+		if _, ok := failed[s.Tid]; !ok {
+			opened = append(opened, s)
+		}
+	}
+	var mismatch []string
+	if len(opened) != 0 {
+		var err error
+		mismatch, err = t.VerifySecrets(supplyChainId, opened)
+		if err != nil {
+			return err
+		}
+	}
+	if len(mismatch) != 0 || len(failed) != 0 {
+		return &MismatchError{Tids: mismatch, Failed: failed}
+	}
+	return nil
+}
+
+// sumSecrets 返回产品ID列表,聚合的秘密值与聚合的盲因子
+func sumSecrets(secrets []ProductSecret) ([]string, uint64, []byte, error) {
+	var pSecret uint64 = 0
+	openings := make([]byte, 32)
+	tids := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		tids = append(tids, s.Tid)
+		pSecret += s.Alpha + s.Beta
+		opening, err := bulletproofs.PedersenAddOpening(s.AlphaOpening, s.BetaOpening)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		openings, err = bulletproofs.PedersenAddOpening(openings, opening)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+	}
+	return tids, pSecret, openings, nil
+}
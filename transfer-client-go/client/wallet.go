@@ -0,0 +1,95 @@
+package client
+
+import (
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"fmt"
+	"transfer-client-go/utils"
+	"transfer-client-go/wallet"
+)
+
+const (
+	READ_PID = "ReadPid"
+	// DefaultGapLimit 恢复时连续未登记多少个伪ID后停止扫描
+	DefaultGapLimit = 20
+)
+
+// ReadPid 查询伪ID登记的公钥,未登记时返回空
+func (t *TransferChainClient) ReadPid(supplyChainId, pid string) ([]byte, error) {
+	pair := utils.NewKeyValuePair(1)
+	utils.AddKeyValue(pair, 0, "pid", []byte(pid))
+	return t.QueryContract(supplyChainId, READ_PID, pair)
+}
+
+// ReadPidKey 读取伪ID登记的公钥并解析
+func (t *TransferChainClient) ReadPidKey(supplyChainId, pid string) (*ecdsa.PublicKey, error) {
+	pkBytes, err := t.ReadPid(supplyChainId, pid)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkBytes) == 0 {
+		return nil, fmt.Errorf("pid not registered:%s", pid)
+	}
+	key, err := x509.ParsePKIXPublicKey(pkBytes)
+	if err != nil {
+		return nil, err
+	}
+	pk, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("pid key is not ecdsa:%s", pid)
+	}
+	return pk, nil
+}
+
+// RegisterDerived 管理员通过AddNewPid批量登记派生的伪ID,遇到错误时停止并返回已完成的结果
+func (t *TransferChainClient) RegisterDerived(supplyChainId string, pids []*wallet.Derived, role string, adminSk *ecdsa.PrivateKey) ([]*common.TxResponse, error) {
+	responses := make([]*common.TxResponse, 0, len(pids))
+	for _, d := range pids {
+		response, err := t.AddNewPidWithRole(supplyChainId, d.Pid, role, &d.Key.PublicKey, adminSk)
+		if err != nil {
+			return responses, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// RecoverPids 由主种子恢复已登记的全部派生伪ID
+// 依次扫描account与index,一个account内连续gapLimit个未登记即认为该account结束(包括index 0),
+// 连续gapLimit个account都没有登记的伪ID时结束扫描
+func (t *TransferChainClient) RecoverPids(supplyChainId string, w *wallet.Wallet, gapLimit int) ([]*wallet.Derived, error) {
+	if gapLimit <= 0 {
+		gapLimit = DefaultGapLimit
+	}
+	var result []*wallet.Derived
+	emptyAccounts := 0
+	for account := 0; emptyAccounts < gapLimit; account++ {
+		found := false
+		gap := 0
+		for index := 0; gap < gapLimit; index++ {
+			d, err := w.Derive(account, index)
+			if err != nil {
+				gap++
+				continue
+			}
+			pk, err := t.ReadPid(supplyChainId, d.Pid)
+			if err != nil {
+				return nil, err
+			}
+			if len(pk) == 0 {
+				gap++
+				continue
+			}
+			gap = 0
+			found = true
+			result = append(result, d)
+		}
+		if found {
+			emptyAccounts = 0
+		} else {
+			emptyAccounts++
+		}
+	}
+	return result, nil
+}
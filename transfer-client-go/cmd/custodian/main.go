@@ -0,0 +1,223 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"transfer-client-go/client"
+	"transfer-client-go/threshold"
+	"transfer-client-go/utils"
+)
+
+// 管理方beta密钥保管人工具
+// deal    生成管理方密钥并拆分为份额,可以用保管人伪ID公钥加密后分发
+// decrypt 保管人为转移客户端导出的请求生成解密份额
+// refresh 保管人生成发给其他保管人的刷新片段
+// apply   保管人应用收到的刷新片段,更新自己的份额与门限配置
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("usage: custodian deal|decrypt|refresh|apply [flags]")
+	}
+	var err error
+	switch os.Args[1] {
+	case "deal":
+		err = deal(os.Args[2:])
+	case "decrypt":
+		err = decrypt(os.Args[2:])
+	case "refresh":
+		err = refresh(os.Args[2:])
+	case "apply":
+		err = apply(os.Args[2:])
+	default:
+		err = fmt.Errorf("unknown command:%s", os.Args[1])
+	}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+func deal(args []string) error {
+	fs := flag.NewFlagSet("deal", flag.ExitOnError)
+	t := fs.Int("t", 2, "shares needed to decrypt")
+	n := fs.Int("n", 3, "number of custodians")
+	out := fs.String("out", "threshold", "output directory")
+	config := fs.String("config", "config/config.yml", "chain client config file")
+	supplyChainId := fs.String("chain", "", "supply chain id, required with -pids")
+	pids := fs.String("pids", "", "comma separated custodian pids, share i is encrypted to the i-th pid")
+	_ = fs.Parse(args)
+	keys, err := custodianKeys(*config, *supplyChainId, *pids, *n)
+	if err != nil {
+		return err
+	}
+	cfg, shares, err := threshold.Deal(*t, *n)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(*out, 0700)
+	if err != nil {
+		return err
+	}
+	for i, share := range shares {
+		err = share.Save(filepath.Join(*out, "share_"+strconv.Itoa(share.Index)+".json"), keys[i])
+		if err != nil {
+			return err
+		}
+	}
+	return cfg.Save(filepath.Join(*out, "threshold.json"))
+}
+
+func decrypt(args []string) error {
+	fs := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	shareFile := fs.String("share", "", "share file")
+	keyFile := fs.String("key", "", "custodian private key file, required for encrypted shares")
+	requestFile := fs.String("requests", "", "requests exported by the transfer client")
+	out := fs.String("out", "", "output file of decryption shares")
+	_ = fs.Parse(args)
+	share, err := threshold.LoadShare(*shareFile, readKey(*keyFile))
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(*requestFile)
+	if err != nil {
+		return err
+	}
+	var requests []threshold.Request
+	err = json.Unmarshal(content, &requests)
+	if err != nil {
+		return err
+	}
+	shares, err := share.DecryptShares(requests)
+	if err != nil {
+		return err
+	}
+	content, err = json.Marshal(shares)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(*out, content, 0644)
+}
+
+func refresh(args []string) error {
+	fs := flag.NewFlagSet("refresh", flag.ExitOnError)
+	cfgFile := fs.String("cfg", "threshold/threshold.json", "threshold config file")
+	from := fs.Int("from", 0, "index of this custodian")
+	out := fs.String("out", "refresh", "output directory")
+	config := fs.String("config", "config/config.yml", "chain client config file")
+	supplyChainId := fs.String("chain", "", "supply chain id, required with -pids")
+	pids := fs.String("pids", "", "comma separated custodian pids in index order, part j is encrypted to the j-th pid")
+	_ = fs.Parse(args)
+	cfg, err := threshold.LoadConfig(*cfgFile)
+	if err != nil {
+		return err
+	}
+	indexes := make([]int, 0, len(cfg.Verify))
+	for i := 1; i <= len(cfg.Verify); i++ {
+		indexes = append(indexes, i)
+	}
+	keys, err := custodianKeys(*config, *supplyChainId, *pids, len(indexes))
+	if err != nil {
+		return err
+	}
+	parts, err := cfg.NewRefresh(*from, indexes)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(*out, 0700)
+	if err != nil {
+		return err
+	}
+	for i, part := range parts {
+		name := fmt.Sprintf("refresh_%d_%d.json", part.From, part.To)
+		err = part.Save(filepath.Join(*out, name), keys[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func apply(args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	cfgFile := fs.String("cfg", "threshold/threshold.json", "threshold config file, updated in place")
+	shareFile := fs.String("share", "", "share file, updated in place")
+	keyFile := fs.String("key", "", "custodian private key file, required for encrypted files")
+	partFiles := fs.String("parts", "", "comma separated refresh parts addressed to this custodian")
+	_ = fs.Parse(args)
+	sk := readKey(*keyFile)
+	cfg, err := threshold.LoadConfig(*cfgFile)
+	if err != nil {
+		return err
+	}
+	share, err := threshold.LoadShare(*shareFile, sk)
+	if err != nil {
+		return err
+	}
+	var parts []*threshold.RefreshPart
+	for _, name := range splitList(*partFiles) {
+		part, err := threshold.LoadRefreshPart(name, sk)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, part)
+	}
+	err = share.Refresh(cfg, parts)
+	if err != nil {
+		return err
+	}
+	err = cfg.Refresh(parts)
+	if err != nil {
+		return err
+	}
+	var pk *ecdsa.PublicKey
+	if sk != nil {
+		pk = &sk.PublicKey
+	}
+	err = share.Save(*shareFile, pk)
+	if err != nil {
+		return err
+	}
+	return cfg.Save(*cfgFile)
+}
+
+// custodianKeys 读取保管人伪ID公钥,未指定pids时返回n个nil,文件以明文保存
+func custodianKeys(config, supplyChainId, pids string, n int) ([]*ecdsa.PublicKey, error) {
+	keys := make([]*ecdsa.PublicKey, n)
+	list := splitList(pids)
+	if len(list) == 0 {
+		return keys, nil
+	}
+	if len(list) != n || supplyChainId == "" {
+		return nil, fmt.Errorf("need chain and %d pids", n)
+	}
+	chainClient, err := client.NewTransferChainClient(config)
+	if err != nil {
+		return nil, err
+	}
+	for i, pid := range list {
+		keys[i], err = chainClient.ReadPidKey(supplyChainId, pid)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func readKey(filename string) *ecdsa.PrivateKey {
+	if filename == "" {
+		return nil
+	}
+	return utils.ReadKey(filename)
+}
+
+func splitList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"transfer-client-go/client"
+	"transfer-client-go/utils"
+)
+
+// 监管方去匿名化工具
+// 使用监管方私钥登记去匿名化请求并解密伪ID托管的真实身份,每次调用都会在链上留下记录。
+func main() {
+	config := flag.String("config", "config/config.yml", "chain client config file")
+	supplyChainId := flag.String("chain", "", "supply chain id")
+	regulator := flag.String("regulator", "", "regulator pid")
+	keyFile := flag.String("key", "", "regulator private key file")
+	pid := flag.String("pid", "", "pid to de-anonymise")
+	reason := flag.String("reason", "", "case reference recorded on chain")
+	history := flag.Bool("log", false, "only print the de-anonymisation log of pid")
+	flag.Parse()
+	if *supplyChainId == "" || *pid == "" {
+		log.Fatal("chain and pid are required")
+	}
+	chainClient, err := client.NewTransferChainClient(*config)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if *history {
+		records, err := chainClient.ReadDeanonLog(*supplyChainId, *pid)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		for _, r := range records {
+			fmt.Println(r.Height, r.TxId, r.Regulator, r.Reason)
+		}
+		return
+	}
+	if *regulator == "" || *keyFile == "" || *reason == "" {
+		log.Fatal("regulator, key and reason are required")
+	}
+	sk := utils.ReadKey(*keyFile)
+	identity, response, err := chainClient.Deanonymize(*supplyChainId, *regulator, *pid, *reason, sk)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	fmt.Println("request recorded in tx", response.TxId)
+	fmt.Println(string(identity))
+}
@@ -7,12 +7,13 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/binary"
+	"fmt"
 	"math/big"
-	"transfer-client-go/utils"
 )
 
 const iv = "abcdabcdabcdabcd"
 
+// Encrypt 以旧版v0格式加密,新上传使用EncryptGama
 func Encrypt(miu *big.Int, secret uint64, opening []byte) ([]byte, []byte, error) {
 	commit, err := bulletproofs.PedersenCommitSpecificOpening(secret, opening)
 	if err != nil {
@@ -38,39 +39,16 @@ func Encrypt(miu *big.Int, secret uint64, opening []byte) ([]byte, []byte, error
 	return gama, commit, nil
 }
 
+// Decrypt 严格解析并解密旧版v0格式的gama,任意版本使用DecryptGama
 func Decrypt(key *big.Int, gama []byte) (uint64, []byte, error) {
-	reader := bytes.NewReader(gama)
-	var length int32
-
-	err := binary.Read(reader, binary.BigEndian, &length)
-	if err != nil {
-		return 0, nil, err
-	}
-
-	len2 := int32(len(gama)) - length - 4
-	ciphertext := make([]byte, length)
-
-	_, err = reader.Read(ciphertext)
+	g, err := ParseGama(gama)
 	if err != nil {
 		return 0, nil, err
 	}
-
-	kc := make([]byte, len2)
-	_, err = reader.Read(kc)
-	if err != nil {
-		return 0, nil, err
-	}
-
-	p := new(big.Int)
-	p.SetBytes(kc)
-	p = p.Mod(p, key)
-
-	k := p.Bytes()
-	if len(k) != 16 {
-		k = utils.BytesCombine(make([]byte, 16-len(k)), k)
+	if g.Version != GamaV0 {
+		return 0, nil, fmt.Errorf("%w:%d", ErrGamaVersion, g.Version)
 	}
-	secret, opening := AesDecrypt(ciphertext, k)
-	return secret, opening, nil
+	return g.Decrypt(key, "", false)
 }
 
 func AesEncrypt(value uint64, opening, key []byte) []byte {
@@ -86,18 +64,25 @@ func AesEncrypt(value uint64, opening, key []byte) []byte {
 	return ciphertext
 }
 
-func AesDecrypt(ciphertext, key []byte) (uint64, []byte) {
-	block, _ := aes.NewCipher(key)
+func AesDecrypt(ciphertext, key []byte) (uint64, []byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%block.BlockSize() != 0 {
+		return 0, nil, ErrGamaLength
+	}
 	decrypter := cipher.NewCBCDecrypter(block, []byte(iv))
 	message := make([]byte, len(ciphertext))
 	decrypter.CryptBlocks(message, ciphertext)
-	message = unPaddingBytes(message)
-	reader := bytes.NewReader(message)
-	var secret uint64
-	_ = binary.Read(reader, binary.BigEndian, &secret)
-	opening := make([]byte, 32)
-	_ = binary.Read(reader, binary.BigEndian, opening)
-	return secret, opening
+	message, err = unpad(message, block.BlockSize())
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(message) != secretSize {
+		return 0, nil, fmt.Errorf("%w:message size %d", ErrGamaLength, len(message))
+	}
+	return binary.BigEndian.Uint64(message), message[8:], nil
 }
 
 func paddingBytes(src []byte, blockSize int) []byte {
@@ -111,14 +96,3 @@ func paddingBytes(src []byte, blockSize int) []byte {
 	//4.返回新的字符串
 	return newBytes
 }
-
-//删除密文末尾分组填充的工具方法
-func unPaddingBytes(src []byte) []byte {
-	//1.求出要处理的切片的长度
-	l := len(src)
-	//2.取出最后一个字符，得到其整型值
-	n := int(src[l-1])
-
-	//3.将切片末尾的number个字节删除
-	return src[:l-n]
-}
@@ -0,0 +1,78 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// ECIES公钥加密
+// 密文为临时公钥(非压缩,65字节)||GCM随机数||AES-GCM密文,
+// 对称密钥为SHA256(标签||共享点横坐标||临时公钥),临时公钥同时作为附加认证数据。
+
+const eciesLabel = "BPOTS-ecies"
+
+// EciesEncrypt 使用P-256公钥加密消息
+func EciesEncrypt(pk *ecdsa.PublicKey, message []byte) ([]byte, error) {
+	curve := pk.Curve
+	ephemeral, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	ephemeralPk := elliptic.Marshal(curve, ephemeral.X, ephemeral.Y)
+	x, _ := curve.ScalarMult(pk.X, pk.Y, ephemeral.D.Bytes())
+	aead, err := eciesCipher(curve, x, ephemeralPk)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	out := append(ephemeralPk, nonce...)
+	return aead.Seal(out, nonce, message, ephemeralPk), nil
+}
+
+// EciesDecrypt 使用P-256私钥解密EciesEncrypt的密文
+func EciesDecrypt(sk *ecdsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	curve := sk.Curve
+	size := 1 + 2*((curve.Params().BitSize+7)/8)
+	if len(ciphertext) < size {
+		return nil, fmt.Errorf("ecies ciphertext too short")
+	}
+	ephemeralPk := ciphertext[:size]
+	ex, ey := elliptic.Unmarshal(curve, ephemeralPk)
+	if ex == nil {
+		return nil, fmt.Errorf("invalid ephemeral key")
+	}
+	x, _ := curve.ScalarMult(ex, ey, sk.D.Bytes())
+	aead, err := eciesCipher(curve, x, ephemeralPk)
+	if err != nil {
+		return nil, err
+	}
+	rest := ciphertext[size:]
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("ecies ciphertext too short")
+	}
+	return aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], ephemeralPk)
+}
+
+func eciesCipher(curve elliptic.Curve, x *big.Int, ephemeralPk []byte) (cipher.AEAD, error) {
+	shared := make([]byte, (curve.Params().BitSize+7)/8)
+	x.FillBytes(shared)
+	hash := sha256.New()
+	hash.Write([]byte(eciesLabel))
+	hash.Write(shared)
+	hash.Write(ephemeralPk)
+	block, err := aes.NewCipher(hash.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
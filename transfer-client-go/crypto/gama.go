@@ -0,0 +1,143 @@
+package crypto
+
+import (
+	"bytes"
+	"chainmaker.org/chainmaker/common/v2/crypto/bulletproofs"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"transfer-client-go/utils"
+)
+
+// gama版本化格式
+// v0为旧格式:int32密文长度||AES-CBC密文||k*miu,由Encrypt与Decrypt处理,密钥部分为CRT方案的k*miu。
+// v1格式:头部(魔数"\xffGM"||版本||alpha/beta标志||密钥封装方案编号||4字节组密钥纪元)||int32长度||
+// 密钥部分||GCM随机数||AES-GCM密文。方案编号见scheme.go,解密方按纪元选择对应纪元的成员密钥。
+// 头部与产品ID一起作为附加认证数据,gama被篡改或被挪用到其他产品、另一侧时解密失败。
+// v0的首字节总是0,与魔数不会冲突。
+
+const (
+	GamaV0 byte = 0
+	GamaV1 byte = 1
+
+	gamaMagic      = "\xffGM"
+	gamaHeaderSize = len(gamaMagic) + 3 + 4
+	sideAlpha      = 'a'
+	sideBeta       = 'b'
+	secretSize     = 8 + 32
+)
+
+// GamaVersion 返回gama的格式版本
+func GamaVersion(gama []byte) byte {
+	if len(gama) > len(gamaMagic) && string(gama[:len(gamaMagic)]) == gamaMagic {
+		return gama[len(gamaMagic)]
+	}
+	return GamaV0
+}
+
+func gamaSide(alpha bool) byte {
+	if alpha {
+		return sideAlpha
+	}
+	return sideBeta
+}
+
+// gamaHeader 构造v1头部
+func gamaHeader(alpha bool, scheme byte, epoch uint32) []byte {
+	header := append([]byte(gamaMagic), GamaV1, gamaSide(alpha), scheme)
+	var e [4]byte
+	binary.BigEndian.PutUint32(e[:], epoch)
+	return append(header, e[:]...)
+}
+
+// EncryptGama 以CRT方案加密产品tid的alpha(alpha为true)或beta,返回gama与承诺
+// epoch为miu所属的组密钥纪元,不使用组密钥管理时为0
+func EncryptGama(miu *big.Int, epoch uint32, tid string, alpha bool, secret uint64, opening []byte) ([]byte, []byte, error) {
+	return SealGama(NewCRTEncryptor(miu, epoch), tid, alpha, secret, opening)
+}
+
+// SealGama 以v1格式加密产品tid的alpha或beta,对称密钥由enc封装,返回gama与承诺
+func SealGama(enc Encryptor, tid string, alpha bool, secret uint64, opening []byte) ([]byte, []byte, error) {
+	commit, err := bulletproofs.PedersenCommitSpecificOpening(secret, opening)
+	if err != nil {
+		return nil, nil, err
+	}
+	k := make([]byte, 16)
+	_, err = rand.Read(k)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPart, err := enc.WrapKey(k)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(keyPart) > maxKeyPartSize {
+		return nil, nil, fmt.Errorf("%w:size %d", ErrGamaKeyPart, len(keyPart))
+	}
+	header := gamaHeader(alpha, enc.Scheme(), enc.Epoch())
+	aead, err := newGCM(k)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	message := make([]byte, secretSize)
+	binary.BigEndian.PutUint64(message, secret)
+	copy(message[8:], opening)
+
+	buffer := bytes.NewBuffer(header)
+	_ = binary.Write(buffer, binary.BigEndian, int32(len(keyPart)))
+	buffer.Write(keyPart)
+	buffer.Write(nonce)
+	buffer.Write(aead.Seal(nil, nonce, message, utils.BytesCombine(header, []byte(tid))))
+	return buffer.Bytes(), commit, nil
+}
+
+// DecryptGama 严格解析并解密任意版本的gama,tid与alpha仅用于校验v1的附加认证数据
+func DecryptGama(key *big.Int, tid string, alpha bool, gama []byte) (uint64, []byte, error) {
+	return DecryptGamaWith(FixedKey(key), tid, alpha, gama)
+}
+
+// DecryptGamaWith 严格解析gama并按其纪元选择成员密钥解密
+func DecryptGamaWith(keys EpochKeys, tid string, alpha bool, gama []byte) (uint64, []byte, error) {
+	return OpenGama(gama, tid, alpha, NewCRTDecryptor(keys))
+}
+
+// OpenGama 严格解析gama,按头部的方案编号从decs中选择Decryptor解密
+func OpenGama(gama []byte, tid string, alpha bool, decs ...Decryptor) (uint64, []byte, error) {
+	g, err := ParseGama(gama)
+	if err != nil {
+		return 0, nil, err
+	}
+	return g.Open(tid, alpha, decs...)
+}
+
+// openAead 解密v1格式,头部连同方案与纪元一起作为附加认证数据
+func (g *Gama) openAead(k []byte, tid string, alpha bool) (uint64, []byte, error) {
+	if g.Side != gamaSide(alpha) {
+		return 0, nil, ErrGamaSide
+	}
+	aead, err := newGCM(k)
+	if err != nil {
+		return 0, nil, err
+	}
+	message, err := aead.Open(nil, g.Nonce, g.Ciphertext, utils.BytesCombine(g.header, []byte(tid)))
+	if err != nil {
+		return 0, nil, ErrGamaAuth
+	}
+	return binary.BigEndian.Uint64(message), message[8:], nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,218 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// gama严格解析
+// 所有从链上读到的gama都先经过ParseGama检查长度、填充与密钥部分大小,错误可以用errors.Is区分类型。
+
+var (
+	ErrGamaMissing   = errors.New("gama not uploaded")
+	ErrGamaTruncated = errors.New("gama truncated")
+	ErrGamaLength    = errors.New("invalid gama length")
+	ErrGamaVersion   = errors.New("unsupported gama version")
+	ErrGamaScheme    = errors.New("no decryptor for gama scheme")
+	ErrGamaSide      = errors.New("gama side not match")
+	ErrGamaKeyPart   = errors.New("invalid gama key part")
+	ErrGamaPadding   = errors.New("invalid gama padding")
+	ErrGamaAuth      = errors.New("gama authentication failed")
+)
+
+const (
+	// maxKeyPartSize 密钥部分的最大字节数,足够容纳4096字节的miu或数百个ECIES接收方
+	maxKeyPartSize = 1 << 16
+	gcmNonceSize   = 12
+	gcmTagSize     = 16
+)
+
+// EpochKeys 按gama头部的纪元提供成员密钥
+type EpochKeys interface {
+	KeyFor(epoch uint32) (*big.Int, error)
+}
+
+type fixedKey struct {
+	key *big.Int
+}
+
+func (f fixedKey) KeyFor(uint32) (*big.Int, error) {
+	return f.key, nil
+}
+
+// FixedKey 不区分纪元,始终使用同一个成员密钥
+func FixedKey(key *big.Int) EpochKeys {
+	return fixedKey{key}
+}
+
+// Gama 解析后的gama
+type Gama struct {
+	Version byte
+	// Side v1中的alpha/beta标志,v0为0
+	Side byte
+	// Scheme v1中的密钥封装方案,v0为SchemeCRT
+	Scheme byte
+	// Epoch v1中的组密钥纪元,v0为0
+	Epoch uint32
+	// KeyPart 封装后的对称密钥,CRT方案为k*miu
+	KeyPart []byte
+	// Nonce v1的GCM随机数
+	Nonce []byte
+	// Ciphertext v0为AES-CBC密文,v1为AES-GCM密文
+	Ciphertext []byte
+	header     []byte
+}
+
+// ParseGama 严格解析gama,不解密
+func ParseGama(raw []byte) (*Gama, error) {
+	if len(raw) == 0 {
+		return nil, ErrGamaMissing
+	}
+	switch GamaVersion(raw) {
+	case GamaV0:
+		return parseV0(raw)
+	case GamaV1:
+		return parseV1(raw)
+	default:
+		return nil, fmt.Errorf("%w:%d", ErrGamaVersion, GamaVersion(raw))
+	}
+}
+
+func parseV0(raw []byte) (*Gama, error) {
+	ciphertext, rest, err := readPrefixed(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("%w:ciphertext size %d", ErrGamaLength, len(ciphertext))
+	}
+	if len(rest) == 0 || len(rest) > maxKeyPartSize {
+		return nil, fmt.Errorf("%w:size %d", ErrGamaKeyPart, len(rest))
+	}
+	return &Gama{Version: GamaV0, KeyPart: rest, Ciphertext: ciphertext}, nil
+}
+
+func parseV1(raw []byte) (*Gama, error) {
+	if len(raw) < gamaHeaderSize {
+		return nil, ErrGamaTruncated
+	}
+	header := raw[:gamaHeaderSize]
+	side := header[len(gamaMagic)+1]
+	if side != sideAlpha && side != sideBeta {
+		return nil, fmt.Errorf("%w:%d", ErrGamaSide, side)
+	}
+	keyPart, rest, err := readPrefixed(raw[gamaHeaderSize:])
+	if err != nil {
+		return nil, err
+	}
+	if len(keyPart) == 0 || len(keyPart) > maxKeyPartSize {
+		return nil, fmt.Errorf("%w:size %d", ErrGamaKeyPart, len(keyPart))
+	}
+	if len(rest) != gcmNonceSize+secretSize+gcmTagSize {
+		return nil, fmt.Errorf("%w:ciphertext size %d", ErrGamaLength, len(rest))
+	}
+	return &Gama{
+		Version:    GamaV1,
+		Side:       side,
+		Scheme:     header[len(gamaMagic)+2],
+		Epoch:      binary.BigEndian.Uint32(header[len(gamaMagic)+3:]),
+		KeyPart:    keyPart,
+		Nonce:      rest[:gcmNonceSize],
+		Ciphertext: rest[gcmNonceSize:],
+		header:     header,
+	}, nil
+}
+
+// SplitGamas 拆分ReadCipher与ReadCipherBatch返回的int32长度前缀列表,依次为每个产品的alpha与beta
+func SplitGamas(content []byte) ([][]byte, error) {
+	var gamas [][]byte
+	for len(content) != 0 {
+		gama, rest, err := readPrefixed(content)
+		if err != nil {
+			return nil, err
+		}
+		gamas = append(gamas, gama)
+		content = rest
+	}
+	return gamas, nil
+}
+
+// readPrefixed 读取int32长度前缀的字段,返回字段与剩余部分
+func readPrefixed(raw []byte) ([]byte, []byte, error) {
+	if len(raw) < 4 {
+		return nil, nil, ErrGamaTruncated
+	}
+	length := int32(binary.BigEndian.Uint32(raw))
+	if length < 0 {
+		return nil, nil, fmt.Errorf("%w:%d", ErrGamaLength, length)
+	}
+	if int(length) > len(raw)-4 {
+		return nil, nil, fmt.Errorf("%w:need %d bytes, have %d", ErrGamaTruncated, length, len(raw)-4)
+	}
+	return raw[4 : 4+length], raw[4+length:], nil
+}
+
+// Key 用成员密钥从CRT方案的k*miu中恢复16字节的对称密钥
+func (g *Gama) Key(key *big.Int) ([]byte, error) {
+	if g.Scheme != SchemeCRT {
+		return nil, fmt.Errorf("%w:%d", ErrGamaScheme, g.Scheme)
+	}
+	return crtKey(g.KeyPart, key)
+}
+
+// Decrypt 解密CRT方案的gama,tid与alpha仅用于校验v1的附加认证数据
+func (g *Gama) Decrypt(key *big.Int, tid string, alpha bool) (uint64, []byte, error) {
+	return g.DecryptWith(FixedKey(key), tid, alpha)
+}
+
+// DecryptWith 按gama的纪元选择成员密钥解密CRT方案的gama
+func (g *Gama) DecryptWith(keys EpochKeys, tid string, alpha bool) (uint64, []byte, error) {
+	return g.Open(tid, alpha, NewCRTDecryptor(keys))
+}
+
+// Open 按gama的方案编号从decs中选择Decryptor解密
+func (g *Gama) Open(tid string, alpha bool, decs ...Decryptor) (uint64, []byte, error) {
+	var dec Decryptor
+	for _, d := range decs {
+		if d.Scheme() == g.Scheme {
+			dec = d
+			break
+		}
+	}
+	if dec == nil {
+		return 0, nil, fmt.Errorf("%w:%d", ErrGamaScheme, g.Scheme)
+	}
+	k, err := dec.UnwrapKey(g.KeyPart, g.Epoch)
+	if err != nil {
+		return 0, nil, err
+	}
+	switch g.Version {
+	case GamaV0:
+		return AesDecrypt(g.Ciphertext, k)
+	case GamaV1:
+		return g.openAead(k, tid, alpha)
+	default:
+		return 0, nil, fmt.Errorf("%w:%d", ErrGamaVersion, g.Version)
+	}
+}
+
+// unpad 校验并去除PKCS#7填充
+func unpad(src []byte, blockSize int) ([]byte, error) {
+	l := len(src)
+	if l == 0 || l%blockSize != 0 {
+		return nil, ErrGamaPadding
+	}
+	n := int(src[l-1])
+	if n == 0 || n > blockSize || n > l {
+		return nil, ErrGamaPadding
+	}
+	for _, b := range src[l-n:] {
+		if int(b) != n {
+			return nil, ErrGamaPadding
+		}
+	}
+	return src[:l-n], nil
+}
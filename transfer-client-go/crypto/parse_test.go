@@ -0,0 +1,139 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func sealTestGama(t *testing.T, tid string, alpha bool) []byte {
+	sk := newPreKey(t)
+	enc, err := NewEciesEncryptor(&sk.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gama, _, err := SealGama(enc, tid, alpha, 42, bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gama
+}
+
+func TestGamaRoundTrip(t *testing.T) {
+	sk := newPreKey(t)
+	enc, err := NewEciesEncryptor(&sk.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opening := bytes.Repeat([]byte{3}, 32)
+	gama, _, err := SealGama(enc, "tid1", true, 42, opening)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := ParseGama(gama)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Version != GamaV1 || g.Side != sideAlpha || g.Scheme != SchemeECIES || g.Epoch != 0 {
+		t.Fatalf("unexpected header:%d %c %d %d", g.Version, g.Side, g.Scheme, g.Epoch)
+	}
+	secret, got, err := OpenGama(gama, "tid1", true, NewEciesDecryptor(sk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != 42 || !bytes.Equal(got, opening) {
+		t.Fatal("round trip mismatch")
+	}
+}
+
+func TestGamaCRTEpoch(t *testing.T) {
+	key := new(big.Int).Lsh(big.NewInt(1), 200)
+	miu := new(big.Int).Add(new(big.Int).Mul(key, big.NewInt(3)), big.NewInt(1))
+	opening := bytes.Repeat([]byte{5}, 32)
+	gama, _, err := EncryptGama(miu, 7, "tid1", false, 9, opening)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := ParseGama(gama)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Scheme != SchemeCRT || g.Epoch != 7 || g.Side != sideBeta {
+		t.Fatalf("unexpected header:%d %d %c", g.Scheme, g.Epoch, g.Side)
+	}
+	secret, got, err := DecryptGama(key, "tid1", false, gama)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != 9 || !bytes.Equal(got, opening) {
+		t.Fatal("round trip mismatch")
+	}
+}
+
+func TestParseGamaRejects(t *testing.T) {
+	gama := sealTestGama(t, "tid1", true)
+	version := append([]byte{}, gama...)
+	version[len(gamaMagic)] = 9
+	side := append([]byte{}, gama...)
+	side[len(gamaMagic)+1] = 'x'
+	keyPart := append([]byte{}, gama...)
+	copy(keyPart[gamaHeaderSize:], []byte{0, 0, 0, 0})
+	cases := []struct {
+		name string
+		raw  []byte
+		err  error
+	}{
+		{"empty", nil, ErrGamaMissing},
+		{"header", gama[:gamaHeaderSize-1], ErrGamaTruncated},
+		{"key part", gama[:gamaHeaderSize+6], ErrGamaTruncated},
+		{"ciphertext", gama[:len(gama)-1], ErrGamaLength},
+		{"trailing", append(append([]byte{}, gama...), 0), ErrGamaLength},
+		{"version", version, ErrGamaVersion},
+		{"side", side, ErrGamaSide},
+		{"empty key part", keyPart, ErrGamaKeyPart},
+		{"v0 length", []byte{0x7f, 0, 0, 0, 1}, ErrGamaTruncated},
+		{"v0 padding", append([]byte{0, 0, 0, 15}, make([]byte, 20)...), ErrGamaLength},
+	}
+	for _, c := range cases {
+		_, err := ParseGama(c.raw)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s:got %v, want %v", c.name, err, c.err)
+		}
+	}
+}
+
+func TestOpenGamaRejects(t *testing.T) {
+	sk := newPreKey(t)
+	enc, err := NewEciesEncryptor(&sk.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gama, _, err := SealGama(enc, "tid1", true, 42, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := NewEciesDecryptor(sk)
+	if _, _, err = OpenGama(gama, "tid2", true, dec); !errors.Is(err, ErrGamaAuth) {
+		t.Errorf("wrong tid:%v", err)
+	}
+	if _, _, err = OpenGama(gama, "tid1", false, dec); !errors.Is(err, ErrGamaSide) {
+		t.Errorf("wrong side:%v", err)
+	}
+	if _, _, err = OpenGama(gama, "tid1", true, NewCRTDecryptor(FixedKey(big.NewInt(7)))); !errors.Is(err, ErrGamaScheme) {
+		t.Errorf("wrong scheme:%v", err)
+	}
+	if _, _, err = OpenGama(gama, "tid1", true, NewEciesDecryptor(newPreKey(t))); !errors.Is(err, ErrGamaKeyPart) {
+		t.Errorf("not a recipient:%v", err)
+	}
+	tampered := append([]byte{}, gama...)
+	tampered[len(tampered)-1] ^= 1
+	if _, _, err = OpenGama(tampered, "tid1", true, dec); !errors.Is(err, ErrGamaAuth) {
+		t.Errorf("tampered ciphertext:%v", err)
+	}
+	header := append([]byte{}, gama...)
+	header[len(gamaMagic)+3] ^= 1
+	if _, _, err = OpenGama(header, "tid1", true, dec); !errors.Is(err, ErrGamaAuth) {
+		t.Errorf("tampered epoch:%v", err)
+	}
+}
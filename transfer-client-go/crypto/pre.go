@@ -0,0 +1,189 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// 代理重加密方案
+// 对称密钥以非配对的单向代理重加密封装:E=r*G,原始接收方的有效标量为每个gama独立的u=H(r*pk),
+// 密钥部分为 E||k异或掩码,掩码由u*E派生。持有者以有效标量s解密,原始接收方用伪ID私钥a
+// 计算a*E=r*pk得到u,伪ID私钥本身从不作为有效标量。
+// 持有者为接收方B生成重加密密钥:随机x,X=x*G,d=H(X||pkB||x*pkB),rk=s/d。
+// 合约把E替换为rk*E并附上X,B计算d=H(X||pkB||b*X)作为新的有效标量,d*rk*E=s*E。
+// 重加密后的gama可以继续重加密给下一任所有者。合约只看到rk与X,得不到s*E。
+// 接收方B同时知道rk与d,可以算出上一任持有者对该gama的有效标量s,但s只能打开这一个gama,
+// 且u由哈希得到、d由B自己的共享点得到,都不能反推出任何一方的伪ID私钥。
+
+const (
+	SchemePRE byte = 2
+
+	prePointSize   = 65
+	preKeyPartSize = prePointSize + 16
+	preReKeyedSize = preKeyPartSize + prePointSize
+)
+
+type preEncryptor struct {
+	pk *ecdsa.PublicKey
+}
+
+// NewPreEncryptor 以代理重加密方案把对称密钥封装给pk,通常为上传者自己的伪ID公钥
+func NewPreEncryptor(pk *ecdsa.PublicKey) Encryptor {
+	return &preEncryptor{pk: pk}
+}
+
+func (e *preEncryptor) Scheme() byte {
+	return SchemePRE
+}
+
+func (e *preEncryptor) Epoch() uint32 {
+	return 0
+}
+
+func (e *preEncryptor) WrapKey(k []byte) ([]byte, error) {
+	curve := e.pk.Curve
+	r, err := randScalar(curve)
+	if err != nil {
+		return nil, err
+	}
+	ex, ey := curve.ScalarBaseMult(r.Bytes())
+	sx, sy := curve.ScalarMult(e.pk.X, e.pk.Y, r.Bytes())
+	u := ownerScalar(curve, sx, sy)
+	px, py := curve.ScalarMult(ex, ey, u.Bytes())
+	keyPart := elliptic.Marshal(curve, ex, ey)
+	return append(keyPart, xorMask(k, curve, px, py)...), nil
+}
+
+type preDecryptor struct {
+	sk *ecdsa.PrivateKey
+}
+
+// NewPreDecryptor 用伪ID私钥解密封装给自己或重加密给自己的对称密钥
+func NewPreDecryptor(sk *ecdsa.PrivateKey) Decryptor {
+	return &preDecryptor{sk: sk}
+}
+
+func (d *preDecryptor) Scheme() byte {
+	return SchemePRE
+}
+
+func (d *preDecryptor) UnwrapKey(keyPart []byte, _ uint32) ([]byte, error) {
+	s, err := preScalar(d.sk, keyPart)
+	if err != nil {
+		return nil, err
+	}
+	curve := d.sk.Curve
+	ex, ey := elliptic.Unmarshal(curve, keyPart[:prePointSize])
+	if ex == nil {
+		return nil, fmt.Errorf("%w:invalid capsule", ErrGamaKeyPart)
+	}
+	px, py := curve.ScalarMult(ex, ey, s.Bytes())
+	return xorMask(keyPart[prePointSize:preKeyPartSize], curve, px, py), nil
+}
+
+// NewReKey 持有者为接收方to生成keyPart所在gama的重加密密钥,返回rk与X
+// keyPart 当前alpha的密钥部分,用于确定持有者对该gama的有效标量,rk中不含伪ID私钥
+func NewReKey(sk *ecdsa.PrivateKey, keyPart []byte, to *ecdsa.PublicKey) ([]byte, []byte, error) {
+	s, err := preScalar(sk, keyPart)
+	if err != nil {
+		return nil, nil, err
+	}
+	curve := sk.Curve
+	x, err := randScalar(curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	xx, xy := curve.ScalarBaseMult(x.Bytes())
+	point := elliptic.Marshal(curve, xx, xy)
+	sx, sy := curve.ScalarMult(to.X, to.Y, x.Bytes())
+	d := reKeyScalar(curve, point, to, sx, sy)
+	n := curve.Params().N
+	rk := new(big.Int).ModInverse(d, n)
+	rk.Mul(rk, s).Mod(rk, n)
+	return rk.FillBytes(make([]byte, 32)), point, nil
+}
+
+// IsReEncrypted 判断代理重加密方案的密钥部分是否已被重加密
+func IsReEncrypted(keyPart []byte) bool {
+	return len(keyPart) == preReKeyedSize
+}
+
+// preScalar 返回sk持有者对keyPart的有效标量
+func preScalar(sk *ecdsa.PrivateKey, keyPart []byte) (*big.Int, error) {
+	switch len(keyPart) {
+	case preKeyPartSize:
+		curve := sk.Curve
+		ex, ey := elliptic.Unmarshal(curve, keyPart[:prePointSize])
+		if ex == nil {
+			return nil, fmt.Errorf("%w:invalid capsule", ErrGamaKeyPart)
+		}
+		sx, sy := curve.ScalarMult(ex, ey, sk.D.Bytes())
+		return ownerScalar(curve, sx, sy), nil
+	case preReKeyedSize:
+		curve := sk.Curve
+		point := keyPart[preKeyPartSize:]
+		xx, xy := elliptic.Unmarshal(curve, point)
+		if xx == nil {
+			return nil, fmt.Errorf("%w:invalid rekey point", ErrGamaKeyPart)
+		}
+		sx, sy := curve.ScalarMult(xx, xy, sk.D.Bytes())
+		return reKeyScalar(curve, point, &sk.PublicKey, sx, sy), nil
+	default:
+		return nil, fmt.Errorf("%w:size %d", ErrGamaKeyPart, len(keyPart))
+	}
+}
+
+// ownerScalar 原始接收方对单个gama的有效标量u=H(r*pk) mod N,结果为0时取1
+func ownerScalar(curve elliptic.Curve, sx, sy *big.Int) *big.Int {
+	h := sha256.New()
+	h.Write([]byte("gama-pre-owner"))
+	h.Write(elliptic.Marshal(curve, sx, sy))
+	u := new(big.Int).SetBytes(h.Sum(nil))
+	u.Mod(u, curve.Params().N)
+	if u.Sign() == 0 {
+		u.SetInt64(1)
+	}
+	return u
+}
+
+// reKeyScalar d=H(X||pkB||共享点) mod N,结果为0时取1
+func reKeyScalar(curve elliptic.Curve, point []byte, to *ecdsa.PublicKey, sx, sy *big.Int) *big.Int {
+	h := sha256.New()
+	h.Write([]byte("gama-pre-rekey"))
+	h.Write(point)
+	h.Write(elliptic.Marshal(curve, to.X, to.Y))
+	h.Write(elliptic.Marshal(curve, sx, sy))
+	d := new(big.Int).SetBytes(h.Sum(nil))
+	d.Mod(d, curve.Params().N)
+	if d.Sign() == 0 {
+		d.SetInt64(1)
+	}
+	return d
+}
+
+// xorMask 用共享点派生的掩码异或16字节的对称密钥
+func xorMask(k []byte, curve elliptic.Curve, px, py *big.Int) []byte {
+	mask := sha256.Sum256(append([]byte("gama-pre-mask"), elliptic.Marshal(curve, px, py)...))
+	out := make([]byte, len(k))
+	for i := range k {
+		out[i] = k[i] ^ mask[i]
+	}
+	return out
+}
+
+func randScalar(curve elliptic.Curve) (*big.Int, error) {
+	n := curve.Params().N
+	for {
+		k, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() > 0 {
+			return k, nil
+		}
+	}
+}
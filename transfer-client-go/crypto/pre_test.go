@@ -0,0 +1,118 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func newPreKey(t *testing.T) *ecdsa.PrivateKey {
+	sk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sk
+}
+
+// reEncrypt 与合约reEncryptGama对密钥部分的变换相同
+func reEncrypt(t *testing.T, keyPart, rk, x []byte) []byte {
+	curve := elliptic.P256()
+	ex, ey := elliptic.Unmarshal(curve, keyPart[:prePointSize])
+	if ex == nil {
+		t.Fatal("invalid capsule")
+	}
+	rx, ry := curve.ScalarMult(ex, ey, rk)
+	out := elliptic.Marshal(curve, rx, ry)
+	out = append(out, keyPart[prePointSize:preKeyPartSize]...)
+	return append(out, x...)
+}
+
+func TestPreRoundTrip(t *testing.T) {
+	a := newPreKey(t)
+	k := bytes.Repeat([]byte{7}, 16)
+	keyPart, err := NewPreEncryptor(&a.PublicKey).WrapKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewPreDecryptor(a).UnwrapKey(keyPart, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, k) {
+		t.Fatal("round trip mismatch")
+	}
+	other, err := NewPreDecryptor(newPreKey(t)).UnwrapKey(keyPart, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(other, k) {
+		t.Fatal("other key decrypted the capsule")
+	}
+}
+
+func TestPreReEncryptChain(t *testing.T) {
+	a, b, c := newPreKey(t), newPreKey(t), newPreKey(t)
+	k := bytes.Repeat([]byte{9}, 16)
+	keyPart, err := NewPreEncryptor(&a.PublicKey).WrapKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rk, x, err := NewReKey(a, keyPart, &b.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toB := reEncrypt(t, keyPart, rk, x)
+	if !IsReEncrypted(toB) {
+		t.Fatal("key part not marked re-encrypted")
+	}
+	got, err := NewPreDecryptor(b).UnwrapKey(toB, 0)
+	if err != nil || !bytes.Equal(got, k) {
+		t.Fatalf("b cannot decrypt:%v", err)
+	}
+	got, _ = NewPreDecryptor(c).UnwrapKey(toB, 0)
+	if bytes.Equal(got, k) {
+		t.Fatal("c decrypted a key part re-encrypted to b")
+	}
+	rk, x, err = NewReKey(b, toB, &c.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toC := reEncrypt(t, toB, rk, x)
+	got, err = NewPreDecryptor(c).UnwrapKey(toC, 0)
+	if err != nil || !bytes.Equal(got, k) {
+		t.Fatalf("c cannot decrypt:%v", err)
+	}
+}
+
+// 接收方用自己的私钥得到d,rk*d只能是上一任对该gama的有效标量,不能是其伪ID私钥
+func TestReKeyDoesNotLeakPidKey(t *testing.T) {
+	a, b := newPreKey(t), newPreKey(t)
+	keyPart, err := NewPreEncryptor(&a.PublicKey).WrapKey(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rk, x, err := NewReKey(a, keyPart, &b.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := preScalar(b, reEncrypt(t, keyPart, rk, x))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := elliptic.P256().Params().N
+	recovered := new(big.Int).Mul(new(big.Int).SetBytes(rk), d)
+	recovered.Mod(recovered, n)
+	if recovered.Cmp(a.D) == 0 {
+		t.Fatal("rk*d reveals the owner's pid key")
+	}
+	s, err := preScalar(a, keyPart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recovered.Cmp(s) != 0 {
+		t.Fatal("rk*d is not the owner's per-gama scalar")
+	}
+}
@@ -0,0 +1,48 @@
+package crypto
+
+import (
+	"chainmaker.org/chainmaker/common/v2/crypto/bulletproofs"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"transfer-client-go/utils"
+)
+
+// SecretBits alpha与beta的取值范围为[0,2^SecretBits),需与合约保持一致
+const SecretBits = 40
+
+// rangeOffset 2^64-2^SecretBits
+const rangeOffset uint64 = 1<<64 - 1<<SecretBits
+
+// NewSecret 随机生成[0,2^SecretBits)内的秘密值与32字节盲因子,盲因子清除最高4位
+func NewSecret() (uint64, []byte, error) {
+	value := make([]byte, 8)
+	_, err := rand.Read(value)
+	if err != nil {
+		return 0, nil, err
+	}
+	opening := make([]byte, 32)
+	_, err = rand.Read(opening)
+	if err != nil {
+		return 0, nil, err
+	}
+	opening[31] &= 0x0f
+	return binary.BigEndian.Uint64(value) & (1<<SecretBits - 1), opening, nil
+}
+
+// ProveSecretRange 生成承诺值位于[0,2^SecretBits)的范围证明,opening需与承诺使用的盲因子相同
+// 证明为字符串列表编码:secret的范围证明,secret+2^64-2^SecretBits的范围证明
+func ProveSecretRange(secret uint64, opening []byte) ([]byte, error) {
+	if secret >= 1<<SecretBits {
+		return nil, fmt.Errorf("secret out of range:%d", secret)
+	}
+	proof, _, err := bulletproofs.ProveSpecificOpening(secret, opening)
+	if err != nil {
+		return nil, err
+	}
+	shifted, _, err := bulletproofs.ProveSpecificOpening(secret+rangeOffset, opening)
+	if err != nil {
+		return nil, err
+	}
+	return utils.EncodeTids([]string{string(proof), string(shifted)}), nil
+}
@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"chainmaker.org/chainmaker/common/v2/crypto/bulletproofs"
+	"testing"
+	"transfer-client-go/utils"
+)
+
+func TestNewSecretInRange(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		secret, opening, err := NewSecret()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if secret >= 1<<SecretBits || len(opening) != 32 || opening[31] > 0x0f {
+			t.Fatalf("secret or opening out of range:%d %x", secret, opening)
+		}
+	}
+}
+
+// 与合约VerifySecretRange的校验方式相同
+func TestProveSecretRange(t *testing.T) {
+	for _, secret := range []uint64{0, 1, 1<<SecretBits - 1} {
+		_, opening, err := NewSecret()
+		if err != nil {
+			t.Fatal(err)
+		}
+		commit, err := bulletproofs.PedersenCommitSpecificOpening(secret, opening)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof, err := ProveSecretRange(secret, opening)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proofs, err := utils.DecodeStrings(proof)
+		if err != nil || len(proofs) != 2 {
+			t.Fatalf("invalid proof encoding:%v", err)
+		}
+		ok, err := bulletproofs.Verify([]byte(proofs[0]), commit)
+		if err != nil || !ok {
+			t.Fatalf("proof of %d rejected:%v", secret, err)
+		}
+		shifted, err := bulletproofs.PedersenAddNum(commit, rangeOffset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, err = bulletproofs.Verify([]byte(proofs[1]), shifted)
+		if err != nil || !ok {
+			t.Fatalf("shifted proof of %d rejected:%v", secret, err)
+		}
+		other, err := bulletproofs.PedersenCommitSpecificOpening(secret+1, opening)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ok, _ = bulletproofs.Verify([]byte(proofs[0]), other)
+		if ok {
+			t.Fatalf("proof of %d accepted for another commitment", secret)
+		}
+	}
+}
+
+func TestProveSecretRangeRejectsOutOfRange(t *testing.T) {
+	for _, secret := range []uint64{1 << SecretBits, 1<<64 - 1} {
+		_, err := ProveSecretRange(secret, make([]byte, 32))
+		if err == nil {
+			t.Fatalf("secret %d accepted", secret)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"transfer-client-go/utils"
+)
+
+// 对称密钥封装方案
+// gama中的secret与opening由随机的16字节对称密钥加密,Encryptor决定如何把该密钥交给授权方,
+// 方案编号记录在v1头部,解密时按编号选择对应的Decryptor。
+// CRT方案的密钥部分为k*miu,由持有素数的成员恢复;ECIES方案的密钥部分为字符串列表编码,
+// 依次为每个接收方的公钥标识与用其伪ID公钥加密的k。代理重加密方案见pre.go。
+
+const (
+	SchemeCRT   byte = 0
+	SchemeECIES byte = 1
+	// SchemeThreshold 管理方私钥由保管人门限共享,见threshold包
+	SchemeThreshold byte = 3
+
+	keyIdSize = 8
+)
+
+// Encryptor 把gama的对称密钥封装给授权方
+type Encryptor interface {
+	Scheme() byte
+	// Epoch 组密钥纪元,不区分纪元的方案为0
+	Epoch() uint32
+	WrapKey(k []byte) ([]byte, error)
+}
+
+// Decryptor 从gama的密钥部分恢复对称密钥
+type Decryptor interface {
+	Scheme() byte
+	UnwrapKey(keyPart []byte, epoch uint32) ([]byte, error)
+}
+
+type crtEncryptor struct {
+	miu   *big.Int
+	epoch uint32
+}
+
+// NewCRTEncryptor 以miu封装对称密钥,epoch为miu所属的组密钥纪元
+func NewCRTEncryptor(miu *big.Int, epoch uint32) Encryptor {
+	return &crtEncryptor{miu: miu, epoch: epoch}
+}
+
+func (c *crtEncryptor) Scheme() byte {
+	return SchemeCRT
+}
+
+func (c *crtEncryptor) Epoch() uint32 {
+	return c.epoch
+}
+
+func (c *crtEncryptor) WrapKey(k []byte) ([]byte, error) {
+	kc := new(big.Int).SetBytes(k)
+	return kc.Mul(kc, c.miu).Bytes(), nil
+}
+
+type crtDecryptor struct {
+	keys EpochKeys
+}
+
+// NewCRTDecryptor 由成员素数恢复对称密钥
+func NewCRTDecryptor(keys EpochKeys) Decryptor {
+	return &crtDecryptor{keys: keys}
+}
+
+func (c *crtDecryptor) Scheme() byte {
+	return SchemeCRT
+}
+
+func (c *crtDecryptor) UnwrapKey(keyPart []byte, epoch uint32) ([]byte, error) {
+	key, err := c.keys.KeyFor(epoch)
+	if err != nil {
+		return nil, err
+	}
+	return crtKey(keyPart, key)
+}
+
+// crtKey 用成员素数从k*miu中恢复16字节的对称密钥
+func crtKey(keyPart []byte, key *big.Int) ([]byte, error) {
+	if key.Sign() <= 0 {
+		return nil, fmt.Errorf("%w:invalid member key", ErrGamaKeyPart)
+	}
+	p := new(big.Int).SetBytes(keyPart)
+	p.Mod(p, key)
+	if p.BitLen() > 128 {
+		return nil, fmt.Errorf("%w:key not match", ErrGamaKeyPart)
+	}
+	k := make([]byte, 16)
+	return p.FillBytes(k), nil
+}
+
+type eciesEncryptor struct {
+	recipients []*ecdsa.PublicKey
+}
+
+// NewEciesEncryptor 把对称密钥分别加密给每个接收方的伪ID公钥
+func NewEciesEncryptor(recipients ...*ecdsa.PublicKey) (Encryptor, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no recipient")
+	}
+	return &eciesEncryptor{recipients: recipients}, nil
+}
+
+func (e *eciesEncryptor) Scheme() byte {
+	return SchemeECIES
+}
+
+func (e *eciesEncryptor) Epoch() uint32 {
+	return 0
+}
+
+func (e *eciesEncryptor) WrapKey(k []byte) ([]byte, error) {
+	fields := make([]string, 0, 2*len(e.recipients))
+	for _, pk := range e.recipients {
+		ciphertext, err := EciesEncrypt(pk, k)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, string(KeyId(pk)), string(ciphertext))
+	}
+	return utils.EncodeTids(fields), nil
+}
+
+type eciesDecryptor struct {
+	sk *ecdsa.PrivateKey
+}
+
+// NewEciesDecryptor 用接收方伪ID私钥恢复对称密钥
+func NewEciesDecryptor(sk *ecdsa.PrivateKey) Decryptor {
+	return &eciesDecryptor{sk: sk}
+}
+
+func (e *eciesDecryptor) Scheme() byte {
+	return SchemeECIES
+}
+
+func (e *eciesDecryptor) UnwrapKey(keyPart []byte, _ uint32) ([]byte, error) {
+	fields, err := utils.DecodeStrings(keyPart)
+	if err != nil {
+		return nil, fmt.Errorf("%w:%s", ErrGamaKeyPart, err.Error())
+	}
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("%w:odd recipient list", ErrGamaKeyPart)
+	}
+	id := KeyId(&e.sk.PublicKey)
+	for i := 0; i < len(fields); i += 2 {
+		if !bytes.Equal([]byte(fields[i]), id) {
+			continue
+		}
+		k, err := EciesDecrypt(e.sk, []byte(fields[i+1]))
+		if err != nil {
+			return nil, fmt.Errorf("%w:%s", ErrGamaAuth, err.Error())
+		}
+		if len(k) != 16 {
+			return nil, fmt.Errorf("%w:key size %d", ErrGamaKeyPart, len(k))
+		}
+		return k, nil
+	}
+	return nil, fmt.Errorf("%w:not a recipient", ErrGamaKeyPart)
+}
+
+// KeyId 接收方公钥标识,为非压缩公钥SHA256的前8字节
+func KeyId(pk *ecdsa.PublicKey) []byte {
+	sum := sha256.Sum256(elliptic.Marshal(pk.Curve, pk.X, pk.Y))
+	return sum[:keyIdSize]
+}
@@ -0,0 +1,239 @@
+package groupkey
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"transfer-client-go/crypto"
+	"transfer-client-go/utils"
+)
+
+// CRT组密钥管理
+// 管理方为每个成员分配一个秘密素数,miu由授权子集成员的素数通过中国剩余定理组合,满足miu≡1(mod p_i)。
+// 每次加入或移除成员纪元加一,gama头部记录加密时的纪元。成员被移除后其素数只对移除前的纪元有效,
+// 重新加入时分配新的素数,因此成员按纪元在自己的Keyring中选择素数解密。
+
+const (
+	// DefaultBits 素数位数,需大于gama中对称密钥的128位
+	DefaultBits = 160
+)
+
+// EpochPrime 成员在纪元区间[From,To)内使用的素数,To为0表示仍然有效
+type EpochPrime struct {
+	From  uint32   `json:"from"`
+	To    uint32   `json:"to"`
+	Prime *big.Int `json:"prime"`
+}
+
+// Keyring 分发给成员的素数记录
+type Keyring struct {
+	Member string        `json:"member"`
+	Primes []*EpochPrime `json:"primes"`
+}
+
+// GroupKey 授权子集在某个纪元下的组密钥
+type GroupKey struct {
+	Miu     *big.Int
+	Epoch   uint32
+	Members []string
+}
+
+// Encryptor 返回以该组密钥封装对称密钥的CRT方案加密器
+func (g *GroupKey) Encryptor() crypto.Encryptor {
+	return crypto.NewCRTEncryptor(g.Miu, g.Epoch)
+}
+
+// Registry 管理方保存的成员登记表
+type Registry struct {
+	Epoch   uint32              `json:"epoch"`
+	Bits    int                 `json:"bits"`
+	Members map[string]*Keyring `json:"members"`
+}
+
+// NewRegistry 创建空登记表,bits不大于128时使用DefaultBits
+func NewRegistry(bits int) *Registry {
+	if bits <= 128 {
+		bits = DefaultBits
+	}
+	return &Registry{Bits: bits, Members: make(map[string]*Keyring)}
+}
+
+// LoadRegistry 从文件读取登记表
+func LoadRegistry(filename string) (*Registry, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	r := new(Registry)
+	err = json.Unmarshal(content, r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Members == nil {
+		r.Members = make(map[string]*Keyring)
+	}
+	return r, nil
+}
+
+// Save 将登记表保存到文件,文件中包含全部成员素数,需妥善保管
+func (r *Registry) Save(filename string) error {
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, content, 0600)
+}
+
+// Active 判断成员当前是否有效
+func (r *Registry) Active(member string) bool {
+	k, ok := r.Members[member]
+	return ok && k.current() != nil
+}
+
+// ActiveMembers 返回当前有效成员,按名称排序
+func (r *Registry) ActiveMembers() []string {
+	var members []string
+	for name := range r.Members {
+		if r.Active(name) {
+			members = append(members, name)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// Add 加入成员并分配新的素数,纪元加一
+func (r *Registry) Add(member string) (*big.Int, error) {
+	if r.Active(member) {
+		return nil, fmt.Errorf("member already active:%s", member)
+	}
+	prime, err := r.newPrime()
+	if err != nil {
+		return nil, err
+	}
+	r.Epoch++
+	k, ok := r.Members[member]
+	if !ok {
+		k = &Keyring{Member: member}
+		r.Members[member] = k
+	}
+	k.Primes = append(k.Primes, &EpochPrime{From: r.Epoch, Prime: prime})
+	return prime, nil
+}
+
+// Remove 移除成员,纪元加一,之后的组密钥不再包含该成员的素数
+func (r *Registry) Remove(member string) error {
+	if !r.Active(member) {
+		return fmt.Errorf("member not active:%s", member)
+	}
+	r.Epoch++
+	r.Members[member].current().To = r.Epoch
+	return nil
+}
+
+// GroupKey 计算当前纪元下授权子集的组密钥,members为空表示全部有效成员
+func (r *Registry) GroupKey(members ...string) (*GroupKey, error) {
+	if len(members) == 0 {
+		members = r.ActiveMembers()
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no active member")
+	}
+	primes := make([]*big.Int, len(members))
+	seen := make(map[string]bool, len(members))
+	for i, member := range members {
+		if seen[member] {
+			return nil, fmt.Errorf("duplicate member:%s", member)
+		}
+		seen[member] = true
+		if !r.Active(member) {
+			return nil, fmt.Errorf("member not active:%s", member)
+		}
+		primes[i] = r.Members[member].current().Prime
+	}
+	return &GroupKey{Miu: utils.BuildCRTKey(primes), Epoch: r.Epoch, Members: members}, nil
+}
+
+// Keyring 导出分发给成员的素数记录
+func (r *Registry) Keyring(member string) (*Keyring, error) {
+	k, ok := r.Members[member]
+	if !ok {
+		return nil, fmt.Errorf("no member:%s", member)
+	}
+	return k, nil
+}
+
+func (r *Registry) newPrime() (*big.Int, error) {
+	for {
+		prime, err := rand.Prime(rand.Reader, r.Bits)
+		if err != nil {
+			return nil, err
+		}
+		if !r.used(prime) {
+			return prime, nil
+		}
+	}
+}
+
+func (r *Registry) used(prime *big.Int) bool {
+	for _, k := range r.Members {
+		for _, p := range k.Primes {
+			if p.Prime.Cmp(prime) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (k *Keyring) current() *EpochPrime {
+	if len(k.Primes) == 0 {
+		return nil
+	}
+	last := k.Primes[len(k.Primes)-1]
+	if last.To != 0 {
+		return nil
+	}
+	return last
+}
+
+// KeyFor 返回成员在纪元epoch使用的素数
+func (k *Keyring) KeyFor(epoch uint32) (*big.Int, error) {
+	for _, p := range k.Primes {
+		if p.From <= epoch && (p.To == 0 || epoch < p.To) {
+			return p.Prime, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no key for epoch %d", k.Member, epoch)
+}
+
+// Decryptor 返回按纪元选择素数的CRT方案解密器
+func (k *Keyring) Decryptor() crypto.Decryptor {
+	return crypto.NewCRTDecryptor(k)
+}
+
+// LoadKeyring 成员从文件读取自己的素数记录
+func LoadKeyring(filename string) (*Keyring, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	k := new(Keyring)
+	err = json.Unmarshal(content, k)
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Save 将素数记录保存到文件
+func (k *Keyring) Save(filename string) error {
+	content, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, content, 0600)
+}
@@ -81,7 +81,7 @@ func TestSingle(name string, chainClient *client.TransferChainClient, f func(*cl
 func Transfer(transactionsAlpha, transactionsBeta, tid []string, size int, chainClient *client.TransferChainClient, key *big.Int, user *ecdsa.PrivateKey) {
 	st := time.Now().UnixNano()
 	txState := client.TransferToTxState(tid, transactionsAlpha, transactionsBeta, size)
-	r, err := chainClient.TransferProduct(test.TestName, txState, key, test.Pid, user)
+	r, err := chainClient.TransferProduct(test.TestName, txState, key, test.Pid, user, user)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
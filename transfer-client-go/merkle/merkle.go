@@ -0,0 +1,94 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+)
+
+// 叶子与内部节点使用不同的前缀,防止以内部节点冒充叶子
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+func LeafHash(data []byte) []byte {
+	hash := sha256.New()
+	hash.Write([]byte{leafPrefix})
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+func nodeHash(left, right []byte) []byte {
+	hash := sha256.New()
+	hash.Write([]byte{nodePrefix})
+	hash.Write(left)
+	hash.Write(right)
+	return hash.Sum(nil)
+}
+
+// levels 自底向上构造默克尔树,奇数个节点时最后一个节点直接提升到上一层
+func levels(leaves [][]byte) [][][]byte {
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = LeafHash(leaf)
+	}
+	tree := [][][]byte{level}
+	for len(level) > 1 {
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, nodeHash(level[i], level[i+1]))
+			} else {
+				next = append(next, level[i])
+			}
+		}
+		tree = append(tree, next)
+		level = next
+	}
+	return tree
+}
+
+// Root 计算leaves的默克尔根,leaves为空时返回nil
+func Root(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		return nil
+	}
+	tree := levels(leaves)
+	return tree[len(tree)-1][0]
+}
+
+// Proof 计算第index个叶子到根的路径
+func Proof(leaves [][]byte, index int) [][]byte {
+	var proof [][]byte
+	tree := levels(leaves)
+	for _, level := range tree[:len(tree)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		index /= 2
+	}
+	return proof
+}
+
+// VerifyProof 校验data是共有size个叶子的默克尔树中第index个叶子
+func VerifyProof(data []byte, proof [][]byte, index, size int, root []byte) bool {
+	hash := LeafHash(data)
+	for size > 1 {
+		sibling := index ^ 1
+		if sibling < size {
+			if len(proof) == 0 {
+				return false
+			}
+			if index%2 == 0 {
+				hash = nodeHash(hash, proof[0])
+			} else {
+				hash = nodeHash(proof[0], hash)
+			}
+			proof = proof[1:]
+		}
+		index /= 2
+		size = (size + 1) / 2
+	}
+	return len(proof) == 0 && bytes.Equal(hash, root)
+}
@@ -0,0 +1,193 @@
+package ringsig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"transfer-client-go/utils"
+)
+
+// 可打开的环签名
+// 签名者把自己的公钥用管理方公钥做ElGamal加密得到(C1,C2),并对环中每个成员i给出"知道P_i的私钥,
+// 且(C1,C2)加密的是P_i"的或证明。验证方只知道签名者是环中某个成员,管理方可以用私钥计算C2-d*C1
+// 得到签名者公钥以打开签名。签名编码为字符串列表:C1,C2,c0,z_0..z_{n-1},w_0..w_{n-1}。
+// 合约中的transfer-contract-go/ringsig需与本包的挑战值计算保持一致。
+
+const label = "BPOTS-ring"
+
+var curve = elliptic.P256()
+
+// Sign 使用环中成员私钥sk对message签名,manager为管理方公钥
+func Sign(message []byte, ring []*ecdsa.PublicKey, manager *ecdsa.PublicKey, sk *ecdsa.PrivateKey) ([]byte, error) {
+	n := len(ring)
+	k := -1
+	for i, pk := range ring {
+		if pk.X.Cmp(sk.X) == 0 && pk.Y.Cmp(sk.Y) == 0 {
+			k = i
+			break
+		}
+	}
+	if k < 0 {
+		return nil, fmt.Errorf("signer not in ring")
+	}
+	order := curve.Params().N
+	r, err := randScalar()
+	if err != nil {
+		return nil, err
+	}
+	c1x, c1y := curve.ScalarBaseMult(r.Bytes())
+	rmx, rmy := curve.ScalarMult(manager.X, manager.Y, r.Bytes())
+	c2x, c2y := curve.Add(ring[k].X, ring[k].Y, rmx, rmy)
+	prefix := challengePrefix(message, ring, manager, c1x, c1y, c2x, c2y)
+
+	c := make([]*big.Int, n)
+	z := make([]*big.Int, n)
+	w := make([]*big.Int, n)
+	a, err := randScalar()
+	if err != nil {
+		return nil, err
+	}
+	b, err := randScalar()
+	if err != nil {
+		return nil, err
+	}
+	ax, ay := curve.ScalarBaseMult(a.Bytes())
+	b1x, b1y := curve.ScalarBaseMult(b.Bytes())
+	b2x, b2y := curve.ScalarMult(manager.X, manager.Y, b.Bytes())
+	c[(k+1)%n] = challenge(prefix, ax, ay, b1x, b1y, b2x, b2y)
+	for j := 1; j < n; j++ {
+		i := (k + j) % n
+		z[i], err = randScalar()
+		if err != nil {
+			return nil, err
+		}
+		w[i], err = randScalar()
+		if err != nil {
+			return nil, err
+		}
+		c[(i+1)%n] = step(prefix, ring[i], manager, c1x, c1y, c2x, c2y, c[i], z[i], w[i])
+	}
+	z[k] = new(big.Int).Mul(c[k], sk.D)
+	z[k].Sub(a, z[k]).Mod(z[k], order)
+	w[k] = new(big.Int).Mul(c[k], r)
+	w[k].Sub(b, w[k]).Mod(w[k], order)
+
+	fields := make([]string, 0, 3+2*n)
+	fields = append(fields, string(elliptic.Marshal(curve, c1x, c1y)), string(elliptic.Marshal(curve, c2x, c2y)), string(scalarBytes(c[0])))
+	for i := 0; i < n; i++ {
+		fields = append(fields, string(scalarBytes(z[i])))
+	}
+	for i := 0; i < n; i++ {
+		fields = append(fields, string(scalarBytes(w[i])))
+	}
+	return utils.EncodeTids(fields), nil
+}
+
+// Verify 验证签名来自环中某个成员
+func Verify(message []byte, ring []*ecdsa.PublicKey, manager *ecdsa.PublicKey, sig []byte) error {
+	n := len(ring)
+	if n == 0 {
+		return fmt.Errorf("empty ring")
+	}
+	fields, err := utils.DecodeStrings(sig)
+	if err != nil {
+		return err
+	}
+	if len(fields) != 3+2*n {
+		return fmt.Errorf("ring signature size not match")
+	}
+	c1x, c1y := elliptic.Unmarshal(curve, []byte(fields[0]))
+	c2x, c2y := elliptic.Unmarshal(curve, []byte(fields[1]))
+	if c1x == nil || c2x == nil {
+		return fmt.Errorf("invalid ring signature point")
+	}
+	prefix := challengePrefix(message, ring, manager, c1x, c1y, c2x, c2y)
+	c0 := new(big.Int).SetBytes([]byte(fields[2]))
+	c := c0
+	for i := 0; i < n; i++ {
+		z := new(big.Int).SetBytes([]byte(fields[3+i]))
+		w := new(big.Int).SetBytes([]byte(fields[3+n+i]))
+		c = step(prefix, ring[i], manager, c1x, c1y, c2x, c2y, c, z, w)
+	}
+	if c.Cmp(c0) != 0 {
+		return fmt.Errorf("ring signature verification failed")
+	}
+	return nil
+}
+
+// Open 管理方打开签名,返回签名者在环中的序号
+func Open(message []byte, ring []*ecdsa.PublicKey, managerSk *ecdsa.PrivateKey, sig []byte) (int, error) {
+	err := Verify(message, ring, &managerSk.PublicKey, sig)
+	if err != nil {
+		return -1, err
+	}
+	fields, _ := utils.DecodeStrings(sig)
+	c1x, c1y := elliptic.Unmarshal(curve, []byte(fields[0]))
+	c2x, c2y := elliptic.Unmarshal(curve, []byte(fields[1]))
+	dx, dy := curve.ScalarMult(c1x, c1y, managerSk.D.Bytes())
+	px, py := curve.Add(c2x, c2y, dx, new(big.Int).Sub(curve.Params().P, dy))
+	for i, pk := range ring {
+		if pk.X.Cmp(px) == 0 && pk.Y.Cmp(py) == 0 {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("signer not found in ring")
+}
+
+// step 由第i个成员的响应值计算下一个挑战值
+func step(prefix []byte, pk, manager *ecdsa.PublicKey, c1x, c1y, c2x, c2y, c, z, w *big.Int) *big.Int {
+	ax, ay := linear(curve.Params().Gx, curve.Params().Gy, z, pk.X, pk.Y, c)
+	b1x, b1y := linear(curve.Params().Gx, curve.Params().Gy, w, c1x, c1y, c)
+	dx, dy := curve.Add(c2x, c2y, pk.X, new(big.Int).Sub(curve.Params().P, pk.Y))
+	b2x, b2y := linear(manager.X, manager.Y, w, dx, dy, c)
+	return challenge(prefix, ax, ay, b1x, b1y, b2x, b2y)
+}
+
+// linear 计算u*P+v*Q
+func linear(px, py, u, qx, qy, v *big.Int) (*big.Int, *big.Int) {
+	x1, y1 := curve.ScalarMult(px, py, u.Bytes())
+	x2, y2 := curve.ScalarMult(qx, qy, v.Bytes())
+	return curve.Add(x1, y1, x2, y2)
+}
+
+func challengePrefix(message []byte, ring []*ecdsa.PublicKey, manager *ecdsa.PublicKey, c1x, c1y, c2x, c2y *big.Int) []byte {
+	var buffer []byte
+	buffer = append(buffer, label...)
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(message)))
+	buffer = append(buffer, size[:]...)
+	buffer = append(buffer, message...)
+	buffer = append(buffer, elliptic.Marshal(curve, manager.X, manager.Y)...)
+	for _, pk := range ring {
+		buffer = append(buffer, elliptic.Marshal(curve, pk.X, pk.Y)...)
+	}
+	buffer = append(buffer, elliptic.Marshal(curve, c1x, c1y)...)
+	return append(buffer, elliptic.Marshal(curve, c2x, c2y)...)
+}
+
+func challenge(prefix []byte, ax, ay, b1x, b1y, b2x, b2y *big.Int) *big.Int {
+	hash := sha256.New()
+	hash.Write(prefix)
+	hash.Write(elliptic.Marshal(curve, ax, ay))
+	hash.Write(elliptic.Marshal(curve, b1x, b1y))
+	hash.Write(elliptic.Marshal(curve, b2x, b2y))
+	c := new(big.Int).SetBytes(hash.Sum(nil))
+	return c.Mod(c, curve.Params().N)
+}
+
+func randScalar() (*big.Int, error) {
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(curve.Params().N, big.NewInt(1)))
+	if err != nil {
+		return nil, err
+	}
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+func scalarBytes(k *big.Int) []byte {
+	b := make([]byte, 32)
+	return k.FillBytes(b)
+}
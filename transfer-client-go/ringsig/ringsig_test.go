@@ -0,0 +1,82 @@
+package ringsig
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"testing"
+)
+
+func newRing(t *testing.T, n int) ([]*ecdsa.PrivateKey, []*ecdsa.PublicKey) {
+	sks := make([]*ecdsa.PrivateKey, n)
+	ring := make([]*ecdsa.PublicKey, n)
+	for i := range sks {
+		sk, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sks[i], ring[i] = sk, &sk.PublicKey
+	}
+	return sks, ring
+}
+
+func TestSignVerifyOpen(t *testing.T) {
+	sks, ring := newRing(t, 4)
+	manager, _ := newRing(t, 1)
+	message := []byte("transfer")
+	for k, sk := range sks {
+		sig, err := Sign(message, ring, &manager[0].PublicKey, sk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = Verify(message, ring, &manager[0].PublicKey, sig); err != nil {
+			t.Fatalf("signer %d rejected:%v", k, err)
+		}
+		i, err := Open(message, ring, manager[0], sig)
+		if err != nil || i != k {
+			t.Fatalf("open signer %d got %d:%v", k, i, err)
+		}
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	sks, ring := newRing(t, 3)
+	manager, _ := newRing(t, 2)
+	message := []byte("transfer")
+	sig, err := Sign(message, ring, &manager[0].PublicKey, sks[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Verify([]byte("transfer2"), ring, &manager[0].PublicKey, sig) == nil {
+		t.Error("wrong message accepted")
+	}
+	if Verify(message, ring, &manager[1].PublicKey, sig) == nil {
+		t.Error("wrong manager accepted")
+	}
+	if Verify(message, ring[:2], &manager[0].PublicKey, sig) == nil {
+		t.Error("smaller ring accepted")
+	}
+	_, others := newRing(t, 1)
+	if Verify(message, []*ecdsa.PublicKey{ring[0], ring[1], others[0]}, &manager[0].PublicKey, sig) == nil {
+		t.Error("replaced ring member accepted")
+	}
+	if Verify(message, ring, &manager[0].PublicKey, nil) == nil {
+		t.Error("empty signature accepted")
+	}
+	tampered := append([]byte{}, sig...)
+	tampered[len(tampered)-1] ^= 1
+	if Verify(message, ring, &manager[0].PublicKey, tampered) == nil {
+		t.Error("tampered signature accepted")
+	}
+	if _, err = Open(message, ring, manager[1], sig); err == nil {
+		t.Error("wrong manager opened the signature")
+	}
+}
+
+func TestSignerNotInRing(t *testing.T) {
+	_, ring := newRing(t, 3)
+	outsider, _ := newRing(t, 1)
+	manager, _ := newRing(t, 1)
+	if _, err := Sign([]byte("transfer"), ring, &manager[0].PublicKey, outsider[0]); err == nil {
+		t.Fatal("outsider signed for the ring")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/sha256"
+	"math/big"
+	"transfer-client-go/utils"
 )
 
 func CalcHash(content []byte) []byte {
@@ -28,3 +30,41 @@ func Sign(content []byte, sk *ecdsa.PrivateKey) ([]byte, []byte, error) {
 	}
 	return rText, sText, nil
 }
+
+// PartialSignature 共有组成员对同一内容的签名
+type PartialSignature struct {
+	Pid string
+	R   []byte
+	S   []byte
+}
+
+// PartialSign 共有组成员pid使用自己的私钥对content签名
+func PartialSign(content []byte, pid string, sk *ecdsa.PrivateKey) (PartialSignature, error) {
+	r, s, err := Sign(content, sk)
+	if err != nil {
+		return PartialSignature{}, err
+	}
+	return PartialSignature{Pid: pid, R: r, S: s}, nil
+}
+
+// CombineSignatures 将收集到的成员签名编码为签名集合,作为合约参数r提交,参数s为空
+func CombineSignatures(parts []PartialSignature) []byte {
+	fields := make([]string, 0, len(parts)*3)
+	for _, part := range parts {
+		fields = append(fields, part.Pid, string(part.R), string(part.S))
+	}
+	return utils.EncodeTids(fields)
+}
+
+// Verify 使用公钥验证content的签名,r,s为十进制整数文本
+func Verify(content, r, s []byte, pk *ecdsa.PublicKey) bool {
+	rInt, ok := new(big.Int).SetString(string(r), 10)
+	if !ok {
+		return false
+	}
+	sInt, ok := new(big.Int).SetString(string(s), 10)
+	if !ok {
+		return false
+	}
+	return ecdsa.Verify(pk, CalcHash(content), rInt, sInt)
+}
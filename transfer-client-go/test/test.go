@@ -115,11 +115,12 @@ func TransferSingleTest(chainClient *client.TransferChainClient) {
 	txBeta := "174c8bf96d74f33cca52b32f991abcb986030ba522fa4955b0190fe133f1171b"
 	ps := utils.ReadPrimeFromFile("prime/p20.txt")
 	sk1 := utils.ReadKey("key/user2.key")
+	owner := utils.ReadKey("key/user1.key")
 	pid := "00000000000000000000000000000000000000000000000000000000000000002222"
 	tid := "test.tid.1"
 	states := make([]client.TxState, 1)
 	states[0] = client.NewTxState(tid, txAlpha, txBeta)
-	_, err := chainClient.TransferProduct(TestName, states, ps[0], pid, sk1)
+	_, err := chainClient.TransferProduct(TestName, states, ps[0], pid, owner, sk1)
 	if err != nil {
 		log.Fatal("e1" + err.Error())
 	}
@@ -176,16 +177,18 @@ func BatchTransferUploadTest(chainClient *client.TransferChainClient, v bool) []
 
 func BatchTransferTest(client *client.TransferChainClient, states []client.TxState, v bool) {
 	ps := utils.ReadPrimeFromFile("prime/p20.txt")
-	var sk *ecdsa.PrivateKey
+	var sk, owner *ecdsa.PrivateKey
 	var pid string
 	if v {
 		sk = utils.ReadKey("key/user2.key")
+		owner = utils.ReadKey("key/user1.key")
 		pid = "00000000000000000000000000000000000000000000000000000000000000002222"
 	} else {
 		sk = utils.ReadKey("key/user1.key")
+		owner = utils.ReadKey("key/user2.key")
 		pid = "00000000000000000000000000000000000000000000000000000000000000001111"
 	}
-	_, err := client.TransferProduct(TestName, states, ps[0], pid, sk)
+	_, err := client.TransferProduct(TestName, states, ps[0], pid, owner, sk)
 	if err != nil {
 		log.Fatal("transfer e1" + err.Error())
 	}
@@ -0,0 +1,199 @@
+package threshold
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"transfer-client-go/crypto"
+)
+
+// 份额刷新
+// 每个参与刷新的保管人生成常数项为0的随机多项式δ,把δ(j)发给保管人j,并公开系数承诺C_k=b_k*G(k>=1)。
+// 保管人j把收到的全部δ(j)加到份额上,配置中的份额公钥按承诺同步更新,管理方公钥Y与已上传的beta保持不变。
+// 刷新后旧份额与新份额无法合成,泄露的旧份额因此失效。全部保管人必须使用同一组刷新片段。
+
+// RefreshPart 保管人From在一次刷新中发给保管人To的片段
+type RefreshPart struct {
+	From        int      `json:"from"`
+	To          int      `json:"to"`
+	Generation  uint32   `json:"generation"`
+	Commitments [][]byte `json:"commitments"`
+	Delta       *big.Int `json:"delta"`
+}
+
+// NewRefresh 保管人from为indexes中的每个保管人生成刷新片段
+func (c *Config) NewRefresh(from int, indexes []int) ([]*RefreshPart, error) {
+	coeffs, err := randPoly(c.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	coeffs[0] = new(big.Int)
+	commitments := make([][]byte, 0, c.Threshold-1)
+	for _, b := range coeffs[1:] {
+		commitments = append(commitments, mulBase(b))
+	}
+	parts := make([]*RefreshPart, 0, len(indexes))
+	for _, j := range indexes {
+		parts = append(parts, &RefreshPart{
+			From:        from,
+			To:          j,
+			Generation:  c.Generation,
+			Commitments: commitments,
+			Delta:       evalPoly(coeffs, j),
+		})
+	}
+	return parts, nil
+}
+
+// Refresh 按刷新片段的承诺更新全部份额公钥,parts中每个参与者只取一个片段
+func (c *Config) Refresh(parts []*RefreshPart) error {
+	contributors, err := c.contributors(parts)
+	if err != nil {
+		return err
+	}
+	for j, verify := range c.Verify {
+		x, y, err := unmarshal(verify)
+		if err != nil {
+			return err
+		}
+		for _, part := range contributors {
+			dx, dy, err := deltaPoint(part.Commitments, j)
+			if err != nil {
+				return err
+			}
+			x, y = curve.Add(x, y, dx, dy)
+		}
+		c.Verify[j] = elliptic.Marshal(curve, x, y)
+	}
+	c.Generation++
+	return nil
+}
+
+// Refresh 保管人校验发给自己的片段并更新份额,c为刷新前的配置
+func (s *Share) Refresh(c *Config, parts []*RefreshPart) error {
+	contributors, err := c.contributors(parts)
+	if err != nil {
+		return err
+	}
+	if s.Generation != c.Generation {
+		return fmt.Errorf("share of generation %d, expect %d", s.Generation, c.Generation)
+	}
+	value := new(big.Int).Set(s.Value)
+	for from := range contributors {
+		var mine *RefreshPart
+		for _, part := range parts {
+			if part.From == from && part.To == s.Index {
+				mine = part
+				break
+			}
+		}
+		if mine == nil {
+			return fmt.Errorf("missing refresh part from %d", from)
+		}
+		dx, dy, err := deltaPoint(mine.Commitments, s.Index)
+		if err != nil {
+			return err
+		}
+		ex, ey := curve.ScalarBaseMult(mine.Delta.Bytes())
+		if ex.Cmp(dx) != 0 || ey.Cmp(dy) != 0 {
+			return fmt.Errorf("refresh part from %d not match commitments", from)
+		}
+		value.Add(value, mine.Delta)
+	}
+	s.Value = value.Mod(value, curve.Params().N)
+	s.Generation++
+	return nil
+}
+
+// contributors 按参与者归并片段,校验代数与承诺数量,同一参与者的承诺必须一致
+func (c *Config) contributors(parts []*RefreshPart) (map[int]*RefreshPart, error) {
+	contributors := make(map[int]*RefreshPart)
+	for _, part := range parts {
+		if part.Generation != c.Generation {
+			return nil, fmt.Errorf("refresh part of generation %d, expect %d", part.Generation, c.Generation)
+		}
+		if len(part.Commitments) != c.Threshold-1 || part.Delta == nil {
+			return nil, fmt.Errorf("invalid refresh part from %d", part.From)
+		}
+		if _, ok := c.Verify[part.From]; !ok {
+			return nil, fmt.Errorf("unknown refresh contributor:%d", part.From)
+		}
+		prev, ok := contributors[part.From]
+		if !ok {
+			contributors[part.From] = part
+			continue
+		}
+		for k := range prev.Commitments {
+			if string(prev.Commitments[k]) != string(part.Commitments[k]) {
+				return nil, fmt.Errorf("inconsistent commitments from %d", part.From)
+			}
+		}
+	}
+	if len(contributors) == 0 {
+		return nil, fmt.Errorf("no refresh part")
+	}
+	return contributors, nil
+}
+
+// deltaPoint 由系数承诺计算δ(j)*G
+func deltaPoint(commitments [][]byte, j int) (*big.Int, *big.Int, error) {
+	var x, y *big.Int
+	n := curve.Params().N
+	power := big.NewInt(1)
+	bj := big.NewInt(int64(j))
+	for _, commitment := range commitments {
+		power.Mul(power, bj).Mod(power, n)
+		cx, cy, err := unmarshal(commitment)
+		if err != nil {
+			return nil, nil, err
+		}
+		tx, ty := curve.ScalarMult(cx, cy, power.Bytes())
+		if x == nil {
+			x, y = tx, ty
+		} else {
+			x, y = curve.Add(x, y, tx, ty)
+		}
+	}
+	if x == nil {
+		return new(big.Int), new(big.Int), nil
+	}
+	return x, y, nil
+}
+
+// LoadRefreshPart 从文件读取刷新片段,sk非空时先用接收保管人私钥解密
+func LoadRefreshPart(filename string, sk *ecdsa.PrivateKey) (*RefreshPart, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if sk != nil {
+		content, err = crypto.EciesDecrypt(sk, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	part := new(RefreshPart)
+	err = json.Unmarshal(content, part)
+	if err != nil {
+		return nil, err
+	}
+	return part, nil
+}
+
+// Save 将刷新片段保存到文件,pk非空时用接收保管人伪ID公钥加密
+func (r *RefreshPart) Save(filename string, pk *ecdsa.PublicKey) error {
+	content, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if pk != nil {
+		content, err = crypto.EciesEncrypt(pk, content)
+		if err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filename, content, 0600)
+}
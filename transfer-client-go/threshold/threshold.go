@@ -0,0 +1,389 @@
+package threshold
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"transfer-client-go/crypto"
+)
+
+// 管理方beta密钥的门限解密
+// 管理方私钥x以Shamir秘密共享拆分给n个保管人,任意t个保管人即可解密,x本身从不重建。
+// beta按门限方案上传:E=r*G,密钥部分为 E||k异或掩码,掩码由r*Y派生,Y=x*G为公开的管理方公钥。
+// 保管人i对每个E给出解密份额D_i=x_i*E并附上DLEQ证明,转移客户端验证后按拉格朗日系数合成r*Y。
+// 配置文件只含公开信息,份额文件可以用保管人伪ID公钥加密后分发。
+
+const (
+	pointSize   = 65
+	keyPartSize = pointSize + 16
+)
+
+var curve = elliptic.P256()
+
+// Config 门限配置,包含管理方公钥与各保管人份额对应的公钥,可公开
+type Config struct {
+	Threshold int `json:"threshold"`
+	// Generation 份额刷新的次数,刷新后旧份额失效
+	Generation uint32         `json:"generation"`
+	PublicKey  []byte         `json:"publicKey"`
+	Verify     map[int][]byte `json:"verify"`
+}
+
+// Share 保管人持有的份额
+type Share struct {
+	Index      int      `json:"index"`
+	Generation uint32   `json:"generation"`
+	Value      *big.Int `json:"value"`
+}
+
+// DecShare 保管人对一个密钥封装E给出的解密份额与DLEQ证明
+type DecShare struct {
+	Index      int      `json:"index"`
+	Generation uint32   `json:"generation"`
+	Capsule    []byte   `json:"capsule"`
+	D          []byte   `json:"d"`
+	C          *big.Int `json:"c"`
+	Z          *big.Int `json:"z"`
+}
+
+// Request 需要保管人解密的beta密钥部分
+type Request struct {
+	Tid     string `json:"tid"`
+	KeyPart []byte `json:"keyPart"`
+}
+
+// Deal 生成管理方私钥并拆分为n个份额,任意t个可以解密
+func Deal(t, n int) (*Config, []*Share, error) {
+	if t < 1 || n < t {
+		return nil, nil, fmt.Errorf("invalid threshold %d of %d", t, n)
+	}
+	coeffs, err := randPoly(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg := &Config{Threshold: t, PublicKey: mulBase(coeffs[0]), Verify: make(map[int][]byte, n)}
+	shares := make([]*Share, n)
+	for i := 1; i <= n; i++ {
+		v := evalPoly(coeffs, i)
+		shares[i-1] = &Share{Index: i, Value: v}
+		cfg.Verify[i] = mulBase(v)
+	}
+	return cfg, shares, nil
+}
+
+// Encryptor 返回以管理方公钥封装对称密钥的门限方案加密器
+func (c *Config) Encryptor() (crypto.Encryptor, error) {
+	x, y, err := unmarshal(c.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptor{x: x, y: y}, nil
+}
+
+type encryptor struct {
+	x, y *big.Int
+}
+
+func (e *encryptor) Scheme() byte {
+	return crypto.SchemeThreshold
+}
+
+func (e *encryptor) Epoch() uint32 {
+	return 0
+}
+
+func (e *encryptor) WrapKey(k []byte) ([]byte, error) {
+	r, err := randScalar()
+	if err != nil {
+		return nil, err
+	}
+	px, py := curve.ScalarMult(e.x, e.y, r.Bytes())
+	return append(mulBase(r), mask(k, px, py)...), nil
+}
+
+// DecryptShare 保管人为密钥部分生成解密份额
+func (s *Share) DecryptShare(keyPart []byte) (*DecShare, error) {
+	ex, ey, err := capsule(keyPart)
+	if err != nil {
+		return nil, err
+	}
+	dx, dy := curve.ScalarMult(ex, ey, s.Value.Bytes())
+	w, err := randScalar()
+	if err != nil {
+		return nil, err
+	}
+	a1 := mulBase(w)
+	a2x, a2y := curve.ScalarMult(ex, ey, w.Bytes())
+	d := elliptic.Marshal(curve, dx, dy)
+	c := challenge(mulBase(s.Value), keyPart[:pointSize], d, a1, elliptic.Marshal(curve, a2x, a2y))
+	n := curve.Params().N
+	z := new(big.Int).Mul(c, s.Value)
+	z.Sub(w, z).Mod(z, n)
+	return &DecShare{Index: s.Index, Generation: s.Generation, Capsule: keyPart[:pointSize], D: d, C: c, Z: z}, nil
+}
+
+// DecryptShares 保管人为一组请求生成解密份额,跳过非门限方案的请求
+func (s *Share) DecryptShares(requests []Request) ([]*DecShare, error) {
+	var shares []*DecShare
+	for _, r := range requests {
+		if len(r.KeyPart) != keyPartSize {
+			continue
+		}
+		d, err := s.DecryptShare(r.KeyPart)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%w", r.Tid, err)
+		}
+		shares = append(shares, d)
+	}
+	return shares, nil
+}
+
+// VerifyShare 用保管人的公开份额公钥校验解密份额的DLEQ证明
+func (c *Config) VerifyShare(d *DecShare) error {
+	if d.Generation != c.Generation {
+		return fmt.Errorf("share %d of generation %d, expect %d", d.Index, d.Generation, c.Generation)
+	}
+	verify, ok := c.Verify[d.Index]
+	if !ok {
+		return fmt.Errorf("unknown share index:%d", d.Index)
+	}
+	yx, yy, err := unmarshal(verify)
+	if err != nil {
+		return err
+	}
+	ex, ey, err := unmarshal(d.Capsule)
+	if err != nil {
+		return err
+	}
+	dx, dy, err := unmarshal(d.D)
+	if err != nil {
+		return err
+	}
+	n := curve.Params().N
+	if d.C == nil || d.Z == nil || d.C.Sign() < 0 || d.C.Cmp(n) >= 0 || d.Z.Sign() < 0 || d.Z.Cmp(n) >= 0 {
+		return fmt.Errorf("invalid proof of share %d", d.Index)
+	}
+	zgx, zgy := curve.ScalarBaseMult(d.Z.Bytes())
+	cyx, cyy := curve.ScalarMult(yx, yy, d.C.Bytes())
+	a1x, a1y := curve.Add(zgx, zgy, cyx, cyy)
+	zex, zey := curve.ScalarMult(ex, ey, d.Z.Bytes())
+	cdx, cdy := curve.ScalarMult(dx, dy, d.C.Bytes())
+	a2x, a2y := curve.Add(zex, zey, cdx, cdy)
+	e := challenge(verify, d.Capsule, d.D, elliptic.Marshal(curve, a1x, a1y), elliptic.Marshal(curve, a2x, a2y))
+	if e.Cmp(d.C) != 0 {
+		return fmt.Errorf("invalid proof of share %d", d.Index)
+	}
+	return nil
+}
+
+// Combine 校验解密份额并合成对称密钥,需要至少Threshold个不同保管人的有效份额
+func (c *Config) Combine(keyPart []byte, shares []*DecShare) ([]byte, error) {
+	if _, _, err := capsule(keyPart); err != nil {
+		return nil, err
+	}
+	points := make(map[int]*DecShare)
+	for _, d := range shares {
+		if !bytes.Equal(d.Capsule, keyPart[:pointSize]) || points[d.Index] != nil {
+			continue
+		}
+		if c.VerifyShare(d) != nil {
+			continue
+		}
+		points[d.Index] = d
+		if len(points) == c.Threshold {
+			break
+		}
+	}
+	if len(points) < c.Threshold {
+		return nil, fmt.Errorf("%w:%d valid shares, need %d", crypto.ErrGamaKeyPart, len(points), c.Threshold)
+	}
+	indexes := make([]int, 0, len(points))
+	for i := range points {
+		indexes = append(indexes, i)
+	}
+	var px, py *big.Int
+	for _, i := range indexes {
+		dx, dy, _ := unmarshal(points[i].D)
+		lx, ly := curve.ScalarMult(dx, dy, lagrange(i, indexes).Bytes())
+		if px == nil {
+			px, py = lx, ly
+		} else {
+			px, py = curve.Add(px, py, lx, ly)
+		}
+	}
+	return mask(keyPart[pointSize:], px, py), nil
+}
+
+// Decryptor 返回使用已收集解密份额的门限方案解密器,用于转移时解密beta
+func (c *Config) Decryptor(shares []*DecShare) crypto.Decryptor {
+	return &decryptor{cfg: c, shares: shares}
+}
+
+type decryptor struct {
+	cfg    *Config
+	shares []*DecShare
+}
+
+func (d *decryptor) Scheme() byte {
+	return crypto.SchemeThreshold
+}
+
+func (d *decryptor) UnwrapKey(keyPart []byte, _ uint32) ([]byte, error) {
+	return d.cfg.Combine(keyPart, d.shares)
+}
+
+// LoadConfig 从文件读取门限配置
+func LoadConfig(filename string) (*Config, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Config)
+	err = json.Unmarshal(content, c)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save 将门限配置保存到文件
+func (c *Config) Save(filename string) error {
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, content, 0644)
+}
+
+// LoadShare 从文件读取份额,sk非空时先用保管人私钥解密
+func LoadShare(filename string, sk *ecdsa.PrivateKey) (*Share, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if sk != nil {
+		content, err = crypto.EciesDecrypt(sk, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s := new(Share)
+	err = json.Unmarshal(content, s)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save 将份额保存到文件,pk非空时用保管人伪ID公钥加密后保存,便于分发
+func (s *Share) Save(filename string, pk *ecdsa.PublicKey) error {
+	content, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if pk != nil {
+		content, err = crypto.EciesEncrypt(pk, content)
+		if err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filename, content, 0600)
+}
+
+// capsule 校验密钥部分长度并解析E
+func capsule(keyPart []byte) (*big.Int, *big.Int, error) {
+	if len(keyPart) != keyPartSize {
+		return nil, nil, fmt.Errorf("%w:size %d", crypto.ErrGamaKeyPart, len(keyPart))
+	}
+	return unmarshal(keyPart[:pointSize])
+}
+
+// lagrange 返回i在indexes上于0处的拉格朗日系数
+func lagrange(i int, indexes []int) *big.Int {
+	n := curve.Params().N
+	num := big.NewInt(1)
+	den := big.NewInt(1)
+	for _, j := range indexes {
+		if j == i {
+			continue
+		}
+		num.Mul(num, big.NewInt(int64(j))).Mod(num, n)
+		den.Mul(den, big.NewInt(int64(j-i))).Mod(den, n)
+	}
+	den.ModInverse(den, n)
+	return num.Mul(num, den).Mod(num, n)
+}
+
+func challenge(parts ...[]byte) *big.Int {
+	h := sha256.New()
+	h.Write([]byte("gama-threshold-dleq"))
+	for _, p := range parts {
+		h.Write(p)
+	}
+	c := new(big.Int).SetBytes(h.Sum(nil))
+	return c.Mod(c, curve.Params().N)
+}
+
+func mask(k []byte, px, py *big.Int) []byte {
+	m := sha256.Sum256(append([]byte("gama-threshold-mask"), elliptic.Marshal(curve, px, py)...))
+	out := make([]byte, len(k))
+	for i := range k {
+		out[i] = k[i] ^ m[i]
+	}
+	return out
+}
+
+func mulBase(k *big.Int) []byte {
+	x, y := curve.ScalarBaseMult(k.Bytes())
+	return elliptic.Marshal(curve, x, y)
+}
+
+func unmarshal(point []byte) (*big.Int, *big.Int, error) {
+	x, y := elliptic.Unmarshal(curve, point)
+	if x == nil {
+		return nil, nil, fmt.Errorf("invalid curve point")
+	}
+	return x, y, nil
+}
+
+// randPoly 生成t个随机系数,常数项为秘密
+func randPoly(t int) ([]*big.Int, error) {
+	coeffs := make([]*big.Int, t)
+	for i := range coeffs {
+		c, err := randScalar()
+		if err != nil {
+			return nil, err
+		}
+		coeffs[i] = c
+	}
+	return coeffs, nil
+}
+
+func evalPoly(coeffs []*big.Int, x int) *big.Int {
+	n := curve.Params().N
+	bx := big.NewInt(int64(x))
+	v := new(big.Int)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		v.Mul(v, bx).Add(v, coeffs[i]).Mod(v, n)
+	}
+	return v
+}
+
+func randScalar() (*big.Int, error) {
+	n := curve.Params().N
+	for {
+		k, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() > 0 {
+			return k, nil
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package threshold
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"transfer-client-go/crypto"
+)
+
+func deal(t *testing.T, threshold, n int) (*Config, []*Share) {
+	cfg, shares, err := Deal(threshold, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg, shares
+}
+
+func wrap(t *testing.T, cfg *Config, k []byte) []byte {
+	enc, err := cfg.Encryptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPart, err := enc.WrapKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyPart
+}
+
+func decShares(t *testing.T, keyPart []byte, shares ...*Share) []*DecShare {
+	out := make([]*DecShare, len(shares))
+	for i, s := range shares {
+		d, err := s.DecryptShare(keyPart)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[i] = d
+	}
+	return out
+}
+
+func TestDealRejectsInvalidThreshold(t *testing.T) {
+	for _, c := range [][2]int{{0, 3}, {4, 3}} {
+		if _, _, err := Deal(c[0], c[1]); err == nil {
+			t.Errorf("threshold %d of %d accepted", c[0], c[1])
+		}
+	}
+}
+
+func TestCombine(t *testing.T) {
+	cfg, shares := deal(t, 2, 3)
+	k := bytes.Repeat([]byte{6}, 16)
+	keyPart := wrap(t, cfg, k)
+	for _, pair := range [][2]int{{0, 1}, {0, 2}, {1, 2}} {
+		got, err := cfg.Combine(keyPart, decShares(t, keyPart, shares[pair[0]], shares[pair[1]]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, k) {
+			t.Fatalf("shares %v combined a wrong key", pair)
+		}
+	}
+	if _, err := cfg.Combine(keyPart, decShares(t, keyPart, shares[0])); err == nil {
+		t.Fatal("combined below threshold")
+	}
+	duplicate := decShares(t, keyPart, shares[0], shares[0])
+	if _, err := cfg.Combine(keyPart, duplicate); err == nil {
+		t.Fatal("combined the same share twice")
+	}
+}
+
+func TestGamaWithThreshold(t *testing.T) {
+	cfg, shares := deal(t, 2, 3)
+	enc, err := cfg.Encryptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opening := bytes.Repeat([]byte{2}, 32)
+	gama, _, err := crypto.SealGama(enc, "tid1", false, 11, opening)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := crypto.ParseGama(gama)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := []Request{{Tid: "tid1", KeyPart: g.KeyPart}}
+	var collected []*DecShare
+	for _, s := range shares[1:] {
+		d, err := s.DecryptShares(requests)
+		if err != nil {
+			t.Fatal(err)
+		}
+		collected = append(collected, d...)
+	}
+	secret, got, err := crypto.OpenGama(gama, "tid1", false, cfg.Decryptor(collected))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != 11 || !bytes.Equal(got, opening) {
+		t.Fatal("round trip mismatch")
+	}
+}
+
+func TestVerifyShareRejects(t *testing.T) {
+	cfg, shares := deal(t, 2, 3)
+	keyPart := wrap(t, cfg, make([]byte, 16))
+	valid := decShares(t, keyPart, shares[0])[0]
+	if err := cfg.VerifyShare(valid); err != nil {
+		t.Fatal(err)
+	}
+	other := decShares(t, wrap(t, cfg, make([]byte, 16)), shares[0])[0]
+
+	tampered := []func(d *DecShare){
+		func(d *DecShare) { d.D = other.D },
+		func(d *DecShare) { d.Capsule = other.Capsule },
+		func(d *DecShare) { d.Index = 2 },
+		func(d *DecShare) { d.Index = 9 },
+		func(d *DecShare) { d.Generation = 1 },
+		func(d *DecShare) { d.Z = new(big.Int).Add(d.Z, big.NewInt(1)) },
+		func(d *DecShare) { d.C = curve.Params().N },
+		func(d *DecShare) { d.Z = nil },
+	}
+	for i, tamper := range tampered {
+		d := *valid
+		tamper(&d)
+		if cfg.VerifyShare(&d) == nil {
+			t.Errorf("tampered share %d accepted", i)
+		}
+	}
+
+	// 伪造的份额被跳过,有效份额不足时合成失败
+	forged := *decShares(t, keyPart, shares[1])[0]
+	forged.D = valid.D
+	if _, err := cfg.Combine(keyPart, []*DecShare{valid, &forged}); err == nil {
+		t.Fatal("combined with a forged share")
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	cfg, shares := deal(t, 2, 3)
+	k := bytes.Repeat([]byte{4}, 16)
+	keyPart := wrap(t, cfg, k)
+	indexes := []int{1, 2, 3}
+	var parts []*RefreshPart
+	for _, from := range []int{1, 2} {
+		p, err := cfg.NewRefresh(from, indexes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, p...)
+	}
+	old := *shares[0]
+	before := &Config{Threshold: cfg.Threshold, Generation: cfg.Generation, PublicKey: cfg.PublicKey, Verify: map[int][]byte{}}
+	for i, v := range cfg.Verify {
+		before.Verify[i] = v
+	}
+	for _, s := range shares {
+		if err := s.Refresh(before, parts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cfg.Refresh(parts); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(cfg.PublicKey, before.PublicKey) {
+		t.Fatal("refresh changed the public key")
+	}
+	got, err := cfg.Combine(keyPart, decShares(t, keyPart, shares[0], shares[2]))
+	if err != nil || !bytes.Equal(got, k) {
+		t.Fatalf("refreshed shares cannot decrypt:%v", err)
+	}
+	if _, err = cfg.Combine(keyPart, decShares(t, keyPart, &old, shares[2])); err == nil {
+		t.Fatal("old share combined after refresh")
+	}
+
+	fresh, err := before.NewRefresh(1, indexes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh[1].Delta = new(big.Int).Add(fresh[1].Delta, big.NewInt(1))
+	s := &Share{Index: 2, Value: big.NewInt(5)}
+	if s.Refresh(before, fresh) == nil {
+		t.Fatal("refresh part not matching commitments accepted")
+	}
+}
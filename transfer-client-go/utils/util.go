@@ -10,6 +10,8 @@ import (
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/binary"
+	"fmt"
+	"io"
 	"log"
 	"math/big"
 	"os"
@@ -139,6 +141,38 @@ func EncodeTids(tids []string) []byte {
 	return buffer.Bytes()
 }
 
+// DecodeStrings 解析合约返回的int32长度前缀编码的字符串列表
+func DecodeStrings(content []byte) ([]string, error) {
+	reader := bytes.NewReader(content)
+	var length int32
+	err := binary.Read(reader, binary.BigEndian, &length)
+	if err != nil {
+		return nil, err
+	}
+	// 每项至少有4字节的长度前缀,超过剩余字节数/4的长度不可能合法,避免按伪造的长度预分配
+	if length < 0 || int(length) > reader.Len()/4 {
+		return nil, fmt.Errorf("invalid list length:%d", length)
+	}
+	list := make([]string, 0, length)
+	for i := 0; i < int(length); i++ {
+		var itemLen int32
+		err := binary.Read(reader, binary.BigEndian, &itemLen)
+		if err != nil {
+			return nil, err
+		}
+		if itemLen < 0 || int(itemLen) > reader.Len() {
+			return nil, fmt.Errorf("invalid item length:%d", itemLen)
+		}
+		item := make([]byte, itemLen)
+		_, err = io.ReadFull(reader, item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, string(item))
+	}
+	return list, nil
+}
+
 func Uint64ToBytes(v uint64) []byte {
 	buffer := bytes.NewBuffer([]byte{})
 	_ = binary.Write(buffer, binary.BigEndian, v)
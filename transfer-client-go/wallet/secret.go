@@ -0,0 +1,66 @@
+package wallet
+
+import (
+	"chainmaker.org/chainmaker/common/v2/crypto/bulletproofs"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"transfer-client-go/crypto"
+)
+
+// 确定性派生秘密值与盲因子
+// alpha或beta的秘密值与盲因子由主秘密、产品ID与所有权序号经HMAC-SHA256派生,两侧使用不同标签,
+// 所有者离线即可重新得到,无需保存本地记录或解密链上gama。
+// 所有权序号为上传时产品所有权历史的条数,产品转出后再转回时序号不同,
+// 不会重用已在转移中公开过的承诺。
+// 秘密值取派生结果的低SecretBits位,盲因子清除最高4位,保证小于ed25519群的阶。
+// 主秘密可以与伪ID钱包共用同一主种子,派生时使用独立的标签。
+
+const secretLabel = "BPOTS-secret-seed"
+
+// SecretDeriver 由主秘密派生每个产品的秘密值与盲因子
+type SecretDeriver struct {
+	key []byte
+}
+
+// NewSecretDeriver 由主秘密创建派生器,主秘密至少16字节
+func NewSecretDeriver(master []byte) (*SecretDeriver, error) {
+	if len(master) < 16 {
+		return nil, fmt.Errorf("master secret too short")
+	}
+	mac := hmac.New(sha256.New, []byte(secretLabel))
+	mac.Write(master)
+	return &SecretDeriver{key: mac.Sum(nil)}, nil
+}
+
+// Derive 派生产品tid在所有权序号epoch下alpha(alpha为true)或beta的秘密值与32字节盲因子
+func (d *SecretDeriver) Derive(tid string, epoch int, alpha bool) (uint64, []byte) {
+	side := "beta"
+	if alpha {
+		side = "alpha"
+	}
+	value := d.expand("value", side, tid, epoch)
+	secret := binary.BigEndian.Uint64(value[:8]) & (1<<crypto.SecretBits - 1)
+	opening := d.expand("opening", side, tid, epoch)
+	opening[31] &= 0x0f
+	return secret, opening
+}
+
+// Commit 计算派生值的承诺,用于与链上承诺比对
+func (d *SecretDeriver) Commit(tid string, epoch int, alpha bool) ([]byte, error) {
+	secret, opening := d.Derive(tid, epoch, alpha)
+	return bulletproofs.PedersenCommitSpecificOpening(secret, opening)
+}
+
+func (d *SecretDeriver) expand(usage, side, tid string, epoch int) []byte {
+	mac := hmac.New(sha256.New, d.key)
+	for _, part := range []string{usage, side, tid, strconv.Itoa(epoch)} {
+		var size [4]byte
+		binary.BigEndian.PutUint32(size[:], uint32(len(part)))
+		mac.Write(size[:])
+		mac.Write([]byte(part))
+	}
+	return mac.Sum(nil)
+}
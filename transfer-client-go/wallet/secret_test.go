@@ -0,0 +1,58 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+	"transfer-client-go/crypto"
+)
+
+func TestNewSecretDeriverRejectsShortMaster(t *testing.T) {
+	if _, err := NewSecretDeriver(make([]byte, 15)); err == nil {
+		t.Fatal("short master secret accepted")
+	}
+}
+
+func TestDeriveDeterministic(t *testing.T) {
+	a, err := NewSecretDeriver(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewSecretDeriver(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1, o1 := a.Derive("tid1", 0, true)
+	s2, o2 := b.Derive("tid1", 0, true)
+	if s1 != s2 || !bytes.Equal(o1, o2) {
+		t.Fatal("derivation is not deterministic")
+	}
+	if s1 >= 1<<crypto.SecretBits || len(o1) != 32 || o1[31] > 0x0f {
+		t.Fatalf("derived value out of range:%d %x", s1, o1)
+	}
+}
+
+func TestDeriveSeparatesInputs(t *testing.T) {
+	d, err := NewSecretDeriver(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewSecretDeriver(bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, opening := d.Derive("tid1", 0, true)
+	variants := map[string]func() (uint64, []byte){
+		"side":   func() (uint64, []byte) { return d.Derive("tid1", 0, false) },
+		"epoch":  func() (uint64, []byte) { return d.Derive("tid1", 1, true) },
+		"tid":    func() (uint64, []byte) { return d.Derive("tid2", 0, true) },
+		"master": func() (uint64, []byte) { return other.Derive("tid1", 0, true) },
+		// 长度前缀保证tid与序号的拼接不会混淆
+		"boundary": func() (uint64, []byte) { return d.Derive("tid", 10, true) },
+	}
+	for name, derive := range variants {
+		s, o := derive()
+		if s == base || bytes.Equal(o, opening) {
+			t.Errorf("%s does not change the derived value", name)
+		}
+	}
+}
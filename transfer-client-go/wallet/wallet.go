@@ -0,0 +1,156 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// 分层派生伪ID
+// 从一个主种子按路径m/account/index派生伪ID密钥对,派生方式与BIP32的强化派生相同,仅使用私钥,
+// 不同路径之间无法关联。account可按批次或交易对手划分,index在同一account内递增。
+// 伪ID为密钥对公钥SHA256的前16字节十六进制文本,丢失本地记录后可由主种子重新派生全部伪ID。
+
+const (
+	masterLabel = "BPOTS-pid-seed"
+	SeedSize    = 32
+)
+
+// Wallet 主种子派生出的伪ID钱包
+type Wallet struct {
+	key   []byte
+	chain []byte
+}
+
+// Derived 派生得到的伪ID与密钥对
+type Derived struct {
+	Account int
+	Index   int
+	Pid     string
+	Key     *ecdsa.PrivateKey
+}
+
+// NewSeed 生成新的主种子
+func NewSeed() ([]byte, error) {
+	seed := make([]byte, SeedSize)
+	_, err := rand.Read(seed)
+	if err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+// SaveSeed 以十六进制文本保存主种子
+func SaveSeed(filename string, seed []byte) error {
+	return os.WriteFile(filename, []byte(hex.EncodeToString(seed)), 0600)
+}
+
+// LoadSeed 读取SaveSeed保存的主种子
+func LoadSeed(filename string) ([]byte, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimSpace(string(content)))
+}
+
+// NewWallet 由主种子构造钱包
+func NewWallet(seed []byte) (*Wallet, error) {
+	if len(seed) < 16 {
+		return nil, fmt.Errorf("seed too short")
+	}
+	mac := hmac.New(sha512.New, []byte(masterLabel))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	if !validScalar(sum[:32]) {
+		return nil, fmt.Errorf("invalid seed")
+	}
+	return &Wallet{key: sum[:32], chain: sum[32:]}, nil
+}
+
+// child 强化派生第i个子密钥
+func (w *Wallet) child(i int) (*Wallet, error) {
+	if i < 0 || i >= 1<<31 {
+		return nil, fmt.Errorf("invalid index:%d", i)
+	}
+	mac := hmac.New(sha512.New, w.chain)
+	mac.Write([]byte{0})
+	mac.Write(w.key)
+	var index [4]byte
+	binary.BigEndian.PutUint32(index[:], uint32(i)|1<<31)
+	mac.Write(index[:])
+	sum := mac.Sum(nil)
+	if !validScalar(sum[:32]) {
+		return nil, fmt.Errorf("invalid child %d, use next index", i)
+	}
+	n := elliptic.P256().Params().N
+	k := new(big.Int).SetBytes(sum[:32])
+	k.Add(k, new(big.Int).SetBytes(w.key))
+	k.Mod(k, n)
+	if k.Sign() == 0 {
+		return nil, fmt.Errorf("invalid child %d, use next index", i)
+	}
+	key := make([]byte, 32)
+	k.FillBytes(key)
+	return &Wallet{key: key, chain: sum[32:]}, nil
+}
+
+// Derive 派生路径m/account/index上的伪ID
+func (w *Wallet) Derive(account, index int) (*Derived, error) {
+	a, err := w.child(account)
+	if err != nil {
+		return nil, err
+	}
+	c, err := a.child(index)
+	if err != nil {
+		return nil, err
+	}
+	curve := elliptic.P256()
+	sk := new(ecdsa.PrivateKey)
+	sk.PublicKey.Curve = curve
+	sk.D = new(big.Int).SetBytes(c.key)
+	sk.PublicKey.X, sk.PublicKey.Y = curve.ScalarBaseMult(c.key)
+	pid, err := PidOf(&sk.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Derived{Account: account, Index: index, Pid: pid, Key: sk}, nil
+}
+
+// DeriveRange 派生account下index从start开始的count个伪ID
+func (w *Wallet) DeriveRange(account, start, count int) ([]*Derived, error) {
+	result := make([]*Derived, 0, count)
+	for i := start; i < start+count; i++ {
+		d, err := w.Derive(account, i)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// PidOf 计算公钥对应的伪ID
+func PidOf(pk *ecdsa.PublicKey) (string, error) {
+	pkBytes, err := x509.MarshalPKIXPublicKey(pk)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(pkBytes)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+func validScalar(b []byte) bool {
+	k := new(big.Int).SetBytes(b)
+	return k.Sign() > 0 && k.Cmp(elliptic.P256().Params().N) < 0
+}
@@ -0,0 +1,75 @@
+package wallet
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewWalletRejectsShortSeed(t *testing.T) {
+	if _, err := NewWallet(make([]byte, 15)); err == nil {
+		t.Fatal("short seed accepted")
+	}
+}
+
+func TestDeriveRecoversFromSeed(t *testing.T) {
+	seed, err := NewSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "seed")
+	if err = SaveSeed(filename, seed); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSeed(filename)
+	if err != nil || !bytes.Equal(loaded, seed) {
+		t.Fatalf("seed not restored:%v", err)
+	}
+	w1, err := NewWallet(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w2, err := NewWallet(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := w1.DeriveRange(0, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := w2.DeriveRange(0, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for i := range first {
+		if first[i].Pid != again[i].Pid || first[i].Key.D.Cmp(again[i].Key.D) != 0 {
+			t.Fatalf("index %d not recovered", i)
+		}
+		pid, err := PidOf(&first[i].Key.PublicKey)
+		if err != nil || pid != first[i].Pid {
+			t.Fatalf("pid of index %d not match its key", i)
+		}
+		seen[first[i].Pid] = true
+	}
+	other, err := w1.Derive(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen[other.Pid] = true
+	if len(seen) != 4 {
+		t.Fatal("derived pids collide across paths")
+	}
+}
+
+func TestDeriveRejectsInvalidIndex(t *testing.T) {
+	w, err := NewWallet(make([]byte, SeedSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range [][2]int{{-1, 0}, {0, -1}} {
+		if _, err = w.Derive(path[0], path[1]); err == nil {
+			t.Errorf("path %v accepted", path)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"strconv"
+	"transfer-contract-go/utils"
+)
+
+// 文件与传感器数据锚定相关代码
+const (
+	AnchorDomain = "anchor."
+)
+
+// Anchor 智能合约中的方法,当前所有者或保管方为产品追加锚定一个文件哈希
+// @contract_arg tid: 产品ID
+// @contract_arg pid: 锚定方的伪ID,必须是当前所有者或保管方
+// @contract_arg kind: 文件类型,例如运单,原产地证书,冷链温度记录
+// @contract_arg hash: 文件哈希
+// @contract_arg uri: 可选,文件的存放位置
+// @contract_arg seq: 该产品当前的锚定记录条数,十进制整数文本形式
+// @contract_arg r: 锚定方椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 锚定方椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) Anchor() protogo.Response {
+	return p.appendAnchor("Anchor", nil)
+}
+
+// AnchorMerkle 智能合约中的方法,以默克尔根批量锚定高频传感器数据
+// @contract_arg hash: 全部数据哈希的默克尔根
+// @contract_arg leaves: 默克尔树叶子个数,十进制整数文本形式
+// 其余参数与Anchor相同
+func (p *OwnershipManagement) AnchorMerkle() protogo.Response {
+	leaves := p.ReadArgs("leaves")
+	n, err := strconv.Atoi(string(leaves))
+	if err != nil || n < 1 {
+		return sdk.Error("invalid leaves:" + string(leaves))
+	}
+	return p.appendAnchor("AnchorMerkle", leaves)
+}
+
+func (p *OwnershipManagement) appendAnchor(action string, leaves []byte) protogo.Response {
+	tid := p.ReadArgs("tid")
+	pid := p.ReadArgs("pid")
+	kind := p.ReadArgs("kind")
+	hash := p.ReadArgs("hash")
+	uri := p.ReadArgs("uri")
+	seq := p.ReadArgs("seq")
+	if len(hash) == 0 {
+		return sdk.Error("empty hash")
+	}
+	content := p.BytesCombine([]byte(action), tid, pid, kind, hash, uri, leaves, seq)
+	err := p.VerifyPid(string(pid), content, p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	owner, err := p.ReadOwner(string(tid))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	custodian, err := p.ReadCustodian(string(tid))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if owner == "" || (string(pid) != owner && string(pid) != custodian) {
+		return sdk.Error("permission deny:not owner or custodian")
+	}
+	count, err := p.LogLength(AnchorDomain, string(tid))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if string(seq) != strconv.Itoa(count) {
+		return sdk.Error("anchor seq not match, expect:" + strconv.Itoa(count))
+	}
+	err = p.appendHistory(AnchorDomain, string(tid), string(pid), string(kind), string(hash), string(uri), string(leaves))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte(action + " success"))
+}
+
+// ReadAnchorsValue 智能合约中的方法,查询产品的全部锚定记录
+// @contract_arg tid: 产品ID
+// 返回值为记录列表编码,每条记录为:锚定方,类型,哈希,位置,默克尔叶子个数(单个文件为空),区块高度,交易ID
+func (p *OwnershipManagement) ReadAnchorsValue() protogo.Response {
+	entries, err := p.ReadLog(AnchorDomain, string(p.ReadArgs("tid")))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success(utils.EncodeStrings(entries))
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"fmt"
+	"strconv"
+	"transfer-contract-go/utils"
+)
+
+// 检验证明相关代码
+// 检验方是角色为inspector的伪ID,由管理员通过AddPid或SetRole登记。
+const (
+	AttestDomain      = "attest."
+	AttestPolicy      = "policy.attestation"
+	RoleInspector     = "inspector"
+	AttestResultPass  = "pass"
+	AttestResultFail  = "fail"
+	attestationFields = 6
+)
+
+// Attestation 一条检验证明
+type Attestation struct {
+	Inspector   string
+	Result      string
+	Certificate string
+	Expire      int64
+}
+
+// Attest 智能合约中的方法,检验方为产品登记检验结果
+// @contract_arg tid: 产品ID
+// @contract_arg inspector: 检验方的伪ID
+// @contract_arg result: 检验结果,pass或fail
+// @contract_arg certificate: 证书哈希
+// @contract_arg expire: 可选,证明失效的时间戳(秒)
+// @contract_arg seq: 该产品当前的检验证明条数,十进制整数文本形式
+// @contract_arg r: 检验方椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 检验方椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) Attest() protogo.Response {
+	tid := p.ReadArgs("tid")
+	inspector := p.ReadArgs("inspector")
+	result := p.ReadArgs("result")
+	certificate := p.ReadArgs("certificate")
+	expire := p.ReadArgs("expire")
+	seq := p.ReadArgs("seq")
+	content := p.BytesCombine([]byte("Attest"), tid, inspector, result, certificate, expire, seq)
+	err := p.VerifyPid(string(inspector), content, p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	role, err := p.ReadRole(string(inspector))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if role != RoleInspector {
+		return sdk.Error("permission deny:not an inspector")
+	}
+	if string(result) != AttestResultPass && string(result) != AttestResultFail {
+		return sdk.Error("invalid result:" + string(result))
+	}
+	if _, err = parseOptionalInt(string(expire)); err != nil {
+		return sdk.Error(err.Error())
+	}
+	if !p.HasProduct(string(tid)) {
+		return sdk.Error("no product:" + string(tid))
+	}
+	count, err := p.LogLength(AttestDomain, string(tid))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if string(seq) != strconv.Itoa(count) {
+		return sdk.Error("attestation seq not match, expect:" + strconv.Itoa(count))
+	}
+	err = p.appendHistory(AttestDomain, string(tid), string(inspector), string(result), string(certificate), string(expire))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("attest success"))
+}
+
+// ReadAttestationsValue 智能合约中的方法,查询产品的全部检验证明
+// @contract_arg tid: 产品ID
+// 返回值为记录列表编码,每条记录为:检验方,结果,证书哈希,失效时间戳,区块高度,交易ID
+func (p *OwnershipManagement) ReadAttestationsValue() protogo.Response {
+	entries, err := p.ReadLog(AttestDomain, string(p.ReadArgs("tid")))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success(utils.EncodeStrings(entries))
+}
+
+// SetAttestationPolicy 智能合约中的方法,管理员设置转移前是否必须持有有效的检验证明
+// @contract_arg required: 为"1"时要求每个被转移的产品都有有效的pass证明
+// @contract_arg seq: 当前管理员操作序号,十进制整数文本形式
+// @contract_arg r: 椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) SetAttestationPolicy() protogo.Response {
+	required := p.ReadArgs("required")
+	seq := p.ReadArgs("seq")
+	err := p.VerifyAdmin(p.BytesCombine([]byte("SetAttestationPolicy"), required, seq), p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.checkAdminSeq(seq)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if string(required) == PolicyEnabled {
+		err = p.WriteState(AttestPolicy, required)
+	} else {
+		err = p.DeleteState(AttestPolicy)
+	}
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("set attestation policy success"))
+}
+
+// ReadAttestations 读取产品的全部检验证明
+func (p *OwnershipManagement) ReadAttestations(tid string) ([]Attestation, error) {
+	entries, err := p.ReadLog(AttestDomain, tid)
+	if err != nil {
+		return nil, err
+	}
+	attestations := make([]Attestation, len(entries))
+	for i, entry := range entries {
+		fields, err := utils.DecodeStrings([]byte(entry))
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) != attestationFields {
+			return nil, fmt.Errorf("invalid attestation record")
+		}
+		expire, err := parseOptionalInt(fields[3])
+		if err != nil {
+			return nil, err
+		}
+		attestations[i] = Attestation{fields[0], fields[1], fields[2], int64(expire)}
+	}
+	return attestations, nil
+}
+
+// CheckAttestations 策略启用时,每个检验方只看其对该产品最近的一条证明:
+// 仍为inspector的检验方中,有任一最近证明为未过期的fail则拒绝,否则至少需要一条未过期的pass
+func (p *OwnershipManagement) CheckAttestations(tidList []string) error {
+	if !p.HasState(AttestPolicy) {
+		return nil
+	}
+	timestamp, err := sdk.Instance.GetTxTimeStamp()
+	if err != nil {
+		return err
+	}
+	now, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return err
+	}
+	inspectors := make(map[string]bool)
+	for _, tid := range tidList {
+		attestations, err := p.ReadAttestations(tid)
+		if err != nil {
+			return err
+		}
+		// 按检验方首次出现的顺序遍历,保证各节点读取角色的顺序一致
+		var order []string
+		latest := make(map[string]Attestation)
+		for _, attestation := range attestations {
+			if _, ok := latest[attestation.Inspector]; !ok {
+				order = append(order, attestation.Inspector)
+			}
+			latest[attestation.Inspector] = attestation
+		}
+		valid := false
+		for _, inspector := range order {
+			attestation := latest[inspector]
+			if attestation.Expire > 0 && now > attestation.Expire {
+				continue
+			}
+			isInspector, ok := inspectors[inspector]
+			if !ok {
+				role, err := p.ReadRole(inspector)
+				if err != nil {
+					return err
+				}
+				isInspector = role == RoleInspector
+				inspectors[inspector] = isInspector
+			}
+			if !isInspector {
+				continue
+			}
+			if attestation.Result == AttestResultFail {
+				return fmt.Errorf("attestation failed for tid:%s", tid)
+			}
+			valid = true
+		}
+		if !valid {
+			return fmt.Errorf("no valid attestation for tid:%s", tid)
+		}
+	}
+	return nil
+}
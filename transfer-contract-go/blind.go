@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"transfer-contract-go/ecdsa_pid"
+	"transfer-contract-go/utils"
+)
+
+// 隐藏产品ID相关代码
+// 隐私模式下产品ID为产品密钥公钥的SHA256十六进制文本,产品密钥由客户端从盐值与真实产品ID派生,
+// 合约只看到隐藏ID。转移隐藏产品时需附带产品密钥对转移内容的签名,证明调用者知道真实产品ID。
+const (
+	TidPkDomain = "tidpk."
+)
+
+// BlindTid 计算产品密钥公钥对应的隐藏产品ID
+func BlindTid(tidPk []byte) string {
+	return hex.EncodeToString(utils.CalcSha256(tidPk))
+}
+
+// WriteTidPk 登记隐藏产品ID对应的产品密钥公钥
+func (p *OwnershipManagement) WriteTidPk(tid string, tidPk []byte) error {
+	if BlindTid(tidPk) != tid {
+		return fmt.Errorf("tid is not the blinded id of tidPk")
+	}
+	return p.WriteState(p.BuildKey(TidPkDomain, tid), tidPk)
+}
+
+// VerifyTidKnowledge 校验tidList中每个隐藏产品ID都附带了产品密钥对content的签名
+// tidSig为字符串列表编码,依次排列隐藏产品ID,r,s;未登记产品密钥的普通产品ID无需签名
+func (p *OwnershipManagement) VerifyTidKnowledge(tidList []string, content, tidSig []byte) error {
+	var sigs map[string][2][]byte
+	for _, tid := range tidList {
+		tidPk, err := p.ReadState(p.BuildKey(TidPkDomain, tid))
+		if err != nil {
+			return err
+		}
+		if len(tidPk) == 0 {
+			continue
+		}
+		if sigs == nil {
+			sigs, err = decodeTidSig(tidSig)
+			if err != nil {
+				return err
+			}
+		}
+		sig, ok := sigs[tid]
+		if !ok {
+			return fmt.Errorf("missing tid proof:%s", tid)
+		}
+		err = ecdsa_pid.VerifySign(tidPk, content, sig[0], sig[1])
+		if err != nil {
+			return fmt.Errorf("tid proof verification failed:%s", tid)
+		}
+	}
+	return nil
+}
+
+func decodeTidSig(tidSig []byte) (map[string][2][]byte, error) {
+	fields, err := utils.DecodeStrings(tidSig)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields)%3 != 0 {
+		return nil, fmt.Errorf("invalid tid proof")
+	}
+	sigs := make(map[string][2][]byte, len(fields)/3)
+	for i := 0; i < len(fields); i += 3 {
+		sigs[fields[i]] = [2][]byte{[]byte(fields[i+1]), []byte(fields[i+2])}
+	}
+	return sigs, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"encoding/hex"
+	"fmt"
+	"transfer-contract-go/ecdsa_pid"
+	"transfer-contract-go/utils"
+)
+
+// 所有者承诺相关代码
+// 保密模式下产品所有者记录为承诺ID:"c."加一次性公钥的SHA256十六进制文本。一次性密钥由接收方
+// 从自己的伪ID私钥与产品ID派生,每个产品各不相同,链上无法按伪ID归并产品。承诺ID与其公钥登记
+// 在伪ID命名空间中,所有者以一次性密钥签名即可完成上传alpha、转移等操作。
+// 接收方以每个一次性私钥对转移内容签名表示接受,承诺ID有意不与任何登记的伪ID关联。
+// 承诺ID没有角色,角色转移策略不适用于转入或转出承诺所有者的产品,见CheckTransferPolicy。
+const (
+	CommitOwnerPrefix = "c."
+)
+
+// CommitOwnerId 计算一次性公钥对应的承诺ID
+func CommitOwnerId(ownerPk []byte) string {
+	return CommitOwnerPrefix + hex.EncodeToString(utils.CalcSha256(ownerPk))
+}
+
+// registerCommitOwner 登记一次性公钥,返回其承诺ID
+func (p *OwnershipManagement) registerCommitOwner(ownerPk []byte) (string, error) {
+	id := CommitOwnerId(ownerPk)
+	pk, err := p.ReadPkByPid(id)
+	if err != nil {
+		return "", err
+	}
+	if len(pk) != 0 {
+		if !bytes.Equal(pk, ownerPk) {
+			return "", fmt.Errorf("commit owner conflict:%s", id)
+		}
+		return id, nil
+	}
+	return id, p.WritePkByPid(id, ownerPk)
+}
+
+// ConfidentialTransfer 智能合约中的方法,把产品转移给各自独立的承诺所有者
+// @contract_arg tid: 产品ID列表编码
+// @contract_arg owners: 新所有者一次性公钥列表编码,与tid一一对应
+// @contract_arg pSecret: 聚合的秘密值
+// @contract_arg opening: 聚合的盲因子
+// @contract_arg sigs: 每个产品当前所有者对转移内容的签名,字符串列表编码,依次排列r,s,与tid一一对应
+// @contract_arg ownerSigs: 接收方以每个一次性私钥对转移内容的签名,格式同sigs
+// @contract_arg tidSig: 隐藏产品ID的产品密钥签名集合,见VerifyTidKnowledge
+func (p *OwnershipManagement) ConfidentialTransfer() protogo.Response {
+	allTids := p.ReadArgs("tid")
+	owners := p.ReadArgs("owners")
+	pSecret := p.ReadArgs("pSecret")
+	opening := p.ReadArgs("opening")
+	content := p.BytesCombine([]byte("ConfidentialTransfer"), allTids, owners, pSecret, opening)
+	tidList, err := utils.DecodeTid(allTids)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	ownerPks, err := utils.DecodeStrings(owners)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	sigs, err := utils.DecodeStrings(p.ReadArgs("sigs"))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	ownerSigs, err := utils.DecodeStrings(p.ReadArgs("ownerSigs"))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if len(ownerPks) != len(tidList) || len(sigs) != 2*len(tidList) || len(ownerSigs) != 2*len(tidList) {
+		return sdk.Error("owners and sigs must match tid")
+	}
+	for i, tid := range tidList {
+		owner, err := p.ReadOwner(tid)
+		if err != nil {
+			return sdk.Error(err.Error())
+		}
+		if owner == "" {
+			return sdk.Error("no product:" + tid)
+		}
+		err = p.VerifyPid(owner, content, []byte(sigs[2*i]), []byte(sigs[2*i+1]))
+		if err != nil {
+			return sdk.Error("permission deny:" + err.Error())
+		}
+		err = ecdsa_pid.VerifySign([]byte(ownerPks[i]), content, []byte(ownerSigs[2*i]), []byte(ownerSigs[2*i+1]))
+		if err != nil {
+			return sdk.Error("recipient not accept:" + err.Error())
+		}
+	}
+	err = p.VerifyTidKnowledge(tidList, content, p.ReadArgs("tidSig"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.VerifyBatchSecret(tidList, pSecret, opening)
+	if err != nil {
+		return sdk.Error("permission deny when confidential transfer product:" + err.Error())
+	}
+	err = p.CheckAttestations(tidList)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	for i, tid := range tidList {
+		id, err := p.registerCommitOwner([]byte(ownerPks[i]))
+		if err != nil {
+			return sdk.Error(err.Error())
+		}
+		err = p.WriteOwner(tid, id)
+		if err != nil {
+			return sdk.Error(err.Error())
+		}
+	}
+	return sdk.Success([]byte("confidential transfer success"))
+}
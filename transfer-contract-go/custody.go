@@ -0,0 +1,116 @@
+package main
+
+import (
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"strconv"
+	"transfer-contract-go/utils"
+)
+
+// 保管权相关代码
+// 保管权与所有权相互独立,未登记保管方时由当前所有者保管。
+const (
+	CustodianDomain  = "custodian."
+	CustodyLogDomain = "custodylog."
+)
+
+// ReadCustodian 读取tid的当前保管方,未登记时返回当前所有者
+func (p *OwnershipManagement) ReadCustodian(tid string) (string, error) {
+	custodian, err := p.ReadState(p.BuildKey(CustodianDomain, tid))
+	if err != nil {
+		return "", err
+	}
+	if len(custodian) == 0 {
+		return p.ReadOwner(tid)
+	}
+	return string(custodian), nil
+}
+
+// HandoffCustody 智能合约中的方法,交出方与接收方共同签名完成保管权交接
+// @contract_arg tid: 产品ID
+// @contract_arg from: 交出方的伪ID,必须是当前保管方
+// @contract_arg to: 接收方的伪ID
+// @contract_arg location: 可选,交接地点的哈希
+// @contract_arg seq: 该产品当前的交接记录条数,十进制整数文本形式
+// @contract_arg rFrom: 交出方椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg sFrom: 交出方椭圆曲线签名中的s，十进制整数文本形式
+// @contract_arg r: 接收方椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 接收方椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) HandoffCustody() protogo.Response {
+	tid := p.ReadArgs("tid")
+	from := p.ReadArgs("from")
+	to := p.ReadArgs("to")
+	location := p.ReadArgs("location")
+	seq := p.ReadArgs("seq")
+	content := p.BytesCombine([]byte("HandoffCustody"), tid, from, to, location, seq)
+	if !p.HasProduct(string(tid)) {
+		return sdk.Error("no product:" + string(tid))
+	}
+	custodian, err := p.ReadCustodian(string(tid))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if custodian != string(from) {
+		return sdk.Error("permission deny:not current custodian")
+	}
+	if !p.IsRegistered(string(to)) {
+		return sdk.Error("receiving pid not registered")
+	}
+	count, err := p.LogLength(CustodyLogDomain, string(tid))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if string(seq) != strconv.Itoa(count) {
+		return sdk.Error("custody seq not match, expect:" + strconv.Itoa(count))
+	}
+	err = p.VerifyPid(string(from), content, p.ReadArgs("rFrom"), p.ReadArgs("sFrom"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.VerifyPid(string(to), content, p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.WriteState(p.BuildKey(CustodianDomain, string(tid)), to)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	err = p.appendHistory(CustodyLogDomain, string(tid), string(from), string(to), string(location))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("handoff custody success"))
+}
+
+// ReadCustodyValue 智能合约中的方法,查询产品当前保管方
+// @contract_arg tid: 产品ID
+// 返回值为字符串列表编码:当前保管方,交接记录条数
+func (p *OwnershipManagement) ReadCustodyValue() protogo.Response {
+	tid := string(p.ReadArgs("tid"))
+	custodian, err := p.ReadCustodian(tid)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	count, err := p.LogLength(CustodyLogDomain, tid)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success(utils.EncodeStrings([]string{custodian, strconv.Itoa(count)}))
+}
+
+// ReadProductHistory 智能合约中的方法,查询产品的所有权历史与保管权交接链
+// @contract_arg tid: 产品ID
+// 返回值为字符串列表编码:所有权记录列表编码,交接记录列表编码
+// 所有权记录为:所有者,区块高度,交易ID;交接记录为:交出方,接收方,地点哈希,区块高度,交易ID
+func (p *OwnershipManagement) ReadProductHistory() protogo.Response {
+	tid := string(p.ReadArgs("tid"))
+	owners, err := p.ReadLog(OwnerLogDomain, tid)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	handoffs, err := p.ReadLog(CustodyLogDomain, tid)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success(utils.EncodeStrings([]string{string(utils.EncodeStrings(owners)), string(utils.EncodeStrings(handoffs))}))
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"fmt"
+	"strconv"
+	"transfer-contract-go/utils"
+)
+
+// 委托转移相关代码
+const (
+	GrantDomain    = "grant."
+	GrantSeqDomain = "grantseq."
+)
+
+// TransferGrant 所有者授予代理方的转移权限
+type TransferGrant struct {
+	Tids   map[string]bool
+	Expire int
+}
+
+// Covers 判断授权在给定区块高度是否覆盖tid,Tids为空表示覆盖所有者的全部产品
+func (g *TransferGrant) Covers(tid string, height int) bool {
+	if height > g.Expire {
+		return false
+	}
+	return len(g.Tids) == 0 || g.Tids[tid]
+}
+
+func (p *OwnershipManagement) grantIndex(owner, delegate string) string {
+	return owner + "." + delegate
+}
+
+func (p *OwnershipManagement) readGrantSeq(owner, delegate string) (int, error) {
+	return p.ReadCounter(p.BuildKey(GrantSeqDomain, p.grantIndex(owner, delegate)))
+}
+
+// checkGrantSeq 校验并递增授权序号,防止授权与撤销签名被重放
+func (p *OwnershipManagement) checkGrantSeq(owner, delegate string, seq []byte) error {
+	count, err := p.readGrantSeq(owner, delegate)
+	if err != nil {
+		return err
+	}
+	if string(seq) != strconv.Itoa(count) {
+		return fmt.Errorf("grant seq not match, expect:%d", count)
+	}
+	return p.WriteCounter(p.BuildKey(GrantSeqDomain, p.grantIndex(owner, delegate)), count+1)
+}
+
+// ReadGrant 读取owner授予delegate的转移权限,不存在时返回nil
+func (p *OwnershipManagement) ReadGrant(owner, delegate string) (*TransferGrant, error) {
+	record, err := p.ReadState(p.BuildKey(GrantDomain, p.grantIndex(owner, delegate)))
+	if err != nil {
+		return nil, err
+	}
+	if len(record) == 0 {
+		return nil, nil
+	}
+	fields, err := utils.DecodeStrings(record)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid grant record")
+	}
+	tids, err := utils.DecodeTid([]byte(fields[0]))
+	if err != nil {
+		return nil, err
+	}
+	expire, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	grant := &TransferGrant{Tids: make(map[string]bool, len(tids)), Expire: expire}
+	for _, tid := range tids {
+		grant.Tids[tid] = true
+	}
+	return grant, nil
+}
+
+// GrantTransfer 智能合约中的方法,所有者授权代理方代为转移产品
+// @contract_arg owner: 所有者的伪ID
+// @contract_arg delegate: 代理方的伪ID
+// @contract_arg tid: 授权的产品ID列表编码,列表为空表示所有者的全部产品
+// @contract_arg expire: 授权失效的区块高度,十进制整数文本形式
+// @contract_arg seq: 当前授权序号,十进制整数文本形式
+// @contract_arg r: 所有者椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 所有者椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) GrantTransfer() protogo.Response {
+	owner := p.ReadArgs("owner")
+	delegate := p.ReadArgs("delegate")
+	tids := p.ReadArgs("tid")
+	expire := p.ReadArgs("expire")
+	seq := p.ReadArgs("seq")
+	rText := p.ReadArgs("r")
+	sText := p.ReadArgs("s")
+	content := p.BytesCombine([]byte("GrantTransfer"), owner, delegate, tids, expire, seq)
+	err := p.VerifyPid(string(owner), content, rText, sText)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	if !p.IsRegistered(string(delegate)) {
+		return sdk.Error("delegate pid not registered")
+	}
+	if _, err = utils.DecodeTid(tids); err != nil {
+		return sdk.Error(err.Error())
+	}
+	expireHeight, err := strconv.Atoi(string(expire))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	height, err := sdk.Instance.GetBlockHeight()
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if expireHeight <= height {
+		return sdk.Error("grant already expired")
+	}
+	err = p.checkGrantSeq(string(owner), string(delegate), seq)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	record := utils.EncodeStrings([]string{string(tids), string(expire)})
+	err = p.WriteState(p.BuildKey(GrantDomain, p.grantIndex(string(owner), string(delegate))), record)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("grant transfer success"))
+}
+
+// RevokeTransfer 智能合约中的方法,所有者撤销代理方的转移权限
+// @contract_arg owner: 所有者的伪ID
+// @contract_arg delegate: 代理方的伪ID
+// @contract_arg seq: 当前授权序号,十进制整数文本形式
+// @contract_arg r: 所有者椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 所有者椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) RevokeTransfer() protogo.Response {
+	owner := p.ReadArgs("owner")
+	delegate := p.ReadArgs("delegate")
+	seq := p.ReadArgs("seq")
+	rText := p.ReadArgs("r")
+	sText := p.ReadArgs("s")
+	content := p.BytesCombine([]byte("RevokeTransfer"), owner, delegate, seq)
+	err := p.VerifyPid(string(owner), content, rText, sText)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.checkGrantSeq(string(owner), string(delegate), seq)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	err = p.DeleteState(p.BuildKey(GrantDomain, p.grantIndex(string(owner), string(delegate))))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("revoke transfer success"))
+}
+
+// ReadGrantValue 智能合约中的方法,查询授权
+// @contract_arg owner: 所有者的伪ID
+// @contract_arg delegate: 代理方的伪ID
+// 返回值为字符串列表编码:当前授权序号,授权记录(无授权时为空)
+func (p *OwnershipManagement) ReadGrantValue() protogo.Response {
+	owner := string(p.ReadArgs("owner"))
+	delegate := string(p.ReadArgs("delegate"))
+	seq, err := p.readGrantSeq(owner, delegate)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	record, err := p.ReadState(p.BuildKey(GrantDomain, p.grantIndex(owner, delegate)))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success(utils.EncodeStrings([]string{strconv.Itoa(seq), string(record)}))
+}
+
+// VerifyDelegate 代替所有者校验:delegate对tidList中每个产品都持有其当前所有者的有效授权,
+// 且delegate对content的签名有效
+func (p *OwnershipManagement) VerifyDelegate(delegate string, tidList []string, content, rText, sText []byte) error {
+	height, err := sdk.Instance.GetBlockHeight()
+	if err != nil {
+		return err
+	}
+	grants := make(map[string]*TransferGrant)
+	for _, tid := range tidList {
+		owner, err := p.ReadOwner(tid)
+		if err != nil {
+			return err
+		}
+		grant, ok := grants[owner]
+		if !ok {
+			grant, err = p.ReadGrant(owner, delegate)
+			if err != nil {
+				return err
+			}
+			grants[owner] = grant
+		}
+		if grant == nil || !grant.Covers(tid, height) {
+			return fmt.Errorf("no valid grant for tid:%s", tid)
+		}
+	}
+	return p.VerifyPid(delegate, content, rText, sText)
+}
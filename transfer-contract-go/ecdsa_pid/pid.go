@@ -2,6 +2,7 @@ package ecdsa_pid
 
 import (
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/x509"
 	"fmt"
 	"math/big"
@@ -30,3 +31,16 @@ func VerifySign(pkBytes, content, rText, sText []byte) error {
 	}
 	return nil
 }
+
+// ParsePk 解析伪ID登记的P-256公钥
+func ParsePk(pkBytes []byte) (*ecdsa.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(pkBytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok || publicKey.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("not a P-256 public key")
+	}
+	return publicKey, nil
+}
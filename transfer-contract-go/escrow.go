@@ -0,0 +1,122 @@
+package main
+
+import (
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"fmt"
+	"strconv"
+	"transfer-contract-go/utils"
+)
+
+// 身份托管相关代码
+// 监管方是角色为regulator的伪ID。AddPid可以附带用监管方公钥加密的真实身份,
+// 监管方通过RequestDeanonymize取回托管密文时会在该伪ID的去匿名化日志中留下记录。
+// 去匿名化日志只是监管方自愿登记的审计记录,不是访问控制:托管密文保存在状态escrow.<pid>中,
+// 也出现在AddPid的交易参数里,监管方可以直接读取状态或交易,或以只读查询调用RequestDeanonymize,
+// 都不会追加日志。密文的保密性只依赖监管方的私钥,没有日志记录不能说明身份未被解密。
+const (
+	EscrowDomain    = "escrow."
+	DeanonLogDomain = "deanonlog."
+	RoleRegulator   = "regulator"
+	escrowFields    = 2
+)
+
+// readEscrow 读取伪ID的托管记录,返回监管方伪ID与密文
+func (p *OwnershipManagement) readEscrow(pid string) (string, string, error) {
+	record, err := p.ReadState(p.BuildKey(EscrowDomain, pid))
+	if err != nil {
+		return "", "", err
+	}
+	if len(record) == 0 {
+		return "", "", fmt.Errorf("no escrow for:%s", pid)
+	}
+	fields, err := utils.DecodeStrings(record)
+	if err != nil {
+		return "", "", err
+	}
+	if len(fields) != escrowFields {
+		return "", "", fmt.Errorf("invalid escrow record")
+	}
+	return fields[0], fields[1], nil
+}
+
+// writeEscrow 校验并保存AddPid附带的托管记录
+func (p *OwnershipManagement) writeEscrow(pid string, escrow []byte) error {
+	fields, err := utils.DecodeStrings(escrow)
+	if err != nil {
+		return err
+	}
+	if len(fields) != escrowFields || len(fields[1]) == 0 {
+		return fmt.Errorf("invalid escrow record")
+	}
+	role, err := p.ReadRole(fields[0])
+	if err != nil {
+		return err
+	}
+	if role != RoleRegulator {
+		return fmt.Errorf("escrow target is not a regulator:%s", fields[0])
+	}
+	return p.WriteState(p.BuildKey(EscrowDomain, pid), escrow)
+}
+
+// RequestDeanonymize 智能合约中的方法,监管方登记去匿名化请求并取回托管密文
+// 日志仅在交易上链时追加,只读查询调用同样返回密文但不留记录,见文件开头的说明
+// @contract_arg regulator: 监管方的伪ID,必须是托管记录指定的监管方
+// @contract_arg pid: 需要去匿名化的伪ID
+// @contract_arg reason: 请求原因,例如案件编号
+// @contract_arg seq: 该伪ID当前的去匿名化日志条数,十进制整数文本形式
+// @contract_arg r: 监管方椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 监管方椭圆曲线签名中的s，十进制整数文本形式
+// 返回值为托管密文
+func (p *OwnershipManagement) RequestDeanonymize() protogo.Response {
+	regulator := p.ReadArgs("regulator")
+	pid := p.ReadArgs("pid")
+	reason := p.ReadArgs("reason")
+	seq := p.ReadArgs("seq")
+	content := p.BytesCombine([]byte("RequestDeanonymize"), regulator, pid, reason, seq)
+	err := p.VerifyPid(string(regulator), content, p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	role, err := p.ReadRole(string(regulator))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if role != RoleRegulator {
+		return sdk.Error("permission deny:not a regulator")
+	}
+	target, ciphertext, err := p.readEscrow(string(pid))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if target != string(regulator) {
+		return sdk.Error("permission deny:escrow held by another regulator")
+	}
+	if len(reason) == 0 {
+		return sdk.Error("reason required")
+	}
+	count, err := p.LogLength(DeanonLogDomain, string(pid))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if string(seq) != strconv.Itoa(count) {
+		return sdk.Error("deanonymize seq not match, expect:" + strconv.Itoa(count))
+	}
+	err = p.appendHistory(DeanonLogDomain, string(pid), string(regulator), string(reason))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	sdk.Instance.EmitEvent("RequestDeanonymize", []string{string(pid), string(regulator)})
+	return sdk.Success([]byte(ciphertext))
+}
+
+// ReadDeanonLogValue 智能合约中的方法,查询伪ID的去匿名化日志
+// @contract_arg pid: 伪ID
+// 返回值为记录列表编码,每条记录为:监管方,原因,区块高度,交易ID
+func (p *OwnershipManagement) ReadDeanonLogValue() protogo.Response {
+	entries, err := p.ReadLog(DeanonLogDomain, string(p.ReadArgs("pid")))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success(utils.EncodeStrings(entries))
+}
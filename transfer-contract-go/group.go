@@ -0,0 +1,149 @@
+package main
+
+import (
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"fmt"
+	"strconv"
+	"strings"
+	"transfer-contract-go/ecdsa_pid"
+	"transfer-contract-go/utils"
+)
+
+// 共有产品相关代码
+// 共有组的组ID与伪ID共用命名空间,可以作为产品所有者、转移接收方使用。
+// 以组ID签名时,参数r为签名集合的字符串列表编码(成员伪ID,r,s依次排列),参数s为空。
+const (
+	GroupDomain = "group."
+)
+
+// OwnerGroup 共有组,需要Threshold个成员签名才能代表组
+type OwnerGroup struct {
+	Members   []string
+	Threshold int
+}
+
+// ReadOwnerGroup 读取共有组,gid不是共有组时返回nil
+func (p *OwnershipManagement) ReadOwnerGroup(gid string) (*OwnerGroup, error) {
+	record, err := p.ReadState(p.BuildKey(GroupDomain, gid))
+	if err != nil {
+		return nil, err
+	}
+	if len(record) == 0 {
+		return nil, nil
+	}
+	fields, err := utils.DecodeStrings(record)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid owner group record")
+	}
+	threshold, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	return &OwnerGroup{Members: fields[1:], Threshold: threshold}, nil
+}
+
+// IsRegistered 判断伪ID,共有组ID或签名组ID是否已登记
+func (p *OwnershipManagement) IsRegistered(pid string) bool {
+	return p.HasState(p.BuildKey(PidDomain, pid)) || p.HasState(p.BuildKey(GroupDomain, pid)) ||
+		p.HasState(p.BuildKey(SignGroupDomain, pid))
+}
+
+// AddOwnerGroup 智能合约中的方法,管理员登记共有组
+// @contract_arg gid: 共有组ID
+// @contract_arg members: 成员伪ID列表编码
+// @contract_arg threshold: 代表共有组所需的签名数,十进制整数文本形式
+// @contract_arg r: 椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) AddOwnerGroup() protogo.Response {
+	gid := p.ReadArgs("gid")
+	members := p.ReadArgs("members")
+	threshold := p.ReadArgs("threshold")
+	err := p.VerifyAdmin(p.BytesCombine([]byte("AddOwnerGroup"), gid, members, threshold), p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	if p.IsRegistered(string(gid)) {
+		return sdk.Error("id already registered")
+	}
+	if strings.HasPrefix(string(gid), CommitOwnerPrefix) {
+		return sdk.Error("id prefix reserved for commit owners:" + CommitOwnerPrefix)
+	}
+	memberList, err := utils.DecodeStrings(members)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	k, err := strconv.Atoi(string(threshold))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if k < 1 || k > len(memberList) {
+		return sdk.Error("invalid threshold")
+	}
+	seen := make(map[string]bool, len(memberList))
+	for _, member := range memberList {
+		if seen[member] {
+			return sdk.Error("duplicate member:" + member)
+		}
+		seen[member] = true
+		if !p.HasState(p.BuildKey(PidDomain, member)) {
+			return sdk.Error("member pid not registered:" + member)
+		}
+	}
+	record := utils.EncodeStrings(append([]string{string(threshold)}, memberList...))
+	err = p.WriteState(p.BuildKey(GroupDomain, string(gid)), record)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("add owner group success"))
+}
+
+// ReadOwnerGroupValue 智能合约中的方法,查询共有组
+// @contract_arg gid: 共有组ID
+// 返回值为字符串列表编码:签名门限,成员伪ID...
+func (p *OwnershipManagement) ReadOwnerGroupValue() protogo.Response {
+	record, err := p.ReadState(p.BuildKey(GroupDomain, string(p.ReadArgs("gid"))))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if len(record) == 0 {
+		return sdk.Error("no owner group")
+	}
+	return sdk.Success(record)
+}
+
+// VerifyGroup 校验签名集合中至少有Threshold个不同成员对content的有效签名
+func (p *OwnershipManagement) VerifyGroup(group *OwnerGroup, content, sigs []byte) error {
+	fields, err := utils.DecodeStrings(sigs)
+	if err != nil {
+		return err
+	}
+	if len(fields)%3 != 0 {
+		return fmt.Errorf("invalid signature set")
+	}
+	members := make(map[string]bool, len(group.Members))
+	for _, member := range group.Members {
+		members[member] = true
+	}
+	signed := make(map[string]bool)
+	for i := 0; i < len(fields); i += 3 {
+		member := fields[i]
+		if !members[member] || signed[member] {
+			continue
+		}
+		pkBytes, err := p.ReadPkByPid(member)
+		if err != nil {
+			return err
+		}
+		if ecdsa_pid.VerifySign(pkBytes, content, []byte(fields[i+1]), []byte(fields[i+2])) == nil {
+			signed[member] = true
+		}
+	}
+	if len(signed) < group.Threshold {
+		return fmt.Errorf("signature verification failed:%d of %d signatures", len(signed), group.Threshold)
+	}
+	return nil
+}
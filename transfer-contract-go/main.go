@@ -8,7 +8,10 @@ import (
 	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
 	"encoding/base64"
 	"encoding/binary"
+	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"transfer-contract-go/ecdsa_pid"
 	"transfer-contract-go/utils"
 )
@@ -24,6 +27,10 @@ const (
 	CipherDomain = "cipher."
 	AdminPid     = "admin"
 	OwnerDomain  = "owner"
+	// OwnerLogDomain 所有权变更历史,不能以OwnerDomain开头,否则会与某个tid的所有者记录冲突
+	OwnerLogDomain = "history.owner."
+	// LogLengthDomain 只追加记录的条数,与记录本身使用不同的前缀
+	LogLengthDomain = "len."
 )
 
 func (p *OwnershipManagement) ReadState(key string) ([]byte, error) {
@@ -34,10 +41,30 @@ func (p *OwnershipManagement) WriteState(key string, value []byte) error {
 	return sdk.Instance.PutStateFromKeyByte(key, value)
 }
 
+func (p *OwnershipManagement) DeleteState(key string) error {
+	return sdk.Instance.DelStateFromKey(key)
+}
+
 func (p *OwnershipManagement) ReadArgs(key string) []byte {
 	return sdk.Instance.GetArgs()[key]
 }
 
+// ReadCounter 读取以十进制文本保存的计数器,不存在时返回0
+func (p *OwnershipManagement) ReadCounter(key string) (int, error) {
+	val, err := p.ReadState(key)
+	if err != nil {
+		return 0, err
+	}
+	if len(val) == 0 {
+		return 0, nil
+	}
+	return strconv.Atoi(string(val))
+}
+
+func (p *OwnershipManagement) WriteCounter(key string, count int) error {
+	return p.WriteState(key, []byte(strconv.Itoa(count)))
+}
+
 func (p *OwnershipManagement) HasState(key string) bool {
 	state, err := p.ReadState(key)
 	if err != nil || len(state) == 0 {
@@ -69,6 +96,9 @@ func (p *OwnershipManagement) UpgradeContract() protogo.Response {
 }
 
 func (p *OwnershipManagement) InvokeContract(method string) protogo.Response {
+	if p.IsPaused(method) {
+		return sdk.Error("contract paused, method rejected:" + method)
+	}
 	switch method {
 	case "AddPid":
 		return p.AddPid()
@@ -86,6 +116,84 @@ func (p *OwnershipManagement) InvokeContract(method string) protogo.Response {
 		return p.ReadCipherValueBatch()
 	case "f":
 		return p.ReadFailReason()
+	case "Pause":
+		return p.Pause()
+	case "Unpause":
+		return p.Unpause()
+	case "ReadPauseLog":
+		return p.ReadPauseLog()
+	case "GrantTransfer":
+		return p.GrantTransfer()
+	case "RevokeTransfer":
+		return p.RevokeTransfer()
+	case "ReadGrant":
+		return p.ReadGrantValue()
+	case "SetRole":
+		return p.SetRole()
+	case "SetTransferPolicy":
+		return p.SetTransferPolicy()
+	case "ReadRole":
+		return p.ReadRoleValue()
+	case "ReadTransferPolicy":
+		return p.ReadTransferPolicyValue()
+	case "OfferTransfer":
+		return p.OfferTransfer()
+	case "AcceptTransfer":
+		return p.AcceptTransfer()
+	case "RejectTransfer":
+		return p.RejectTransfer()
+	case "CancelTransfer":
+		return p.CancelTransfer()
+	case "ReadOffer":
+		return p.ReadOfferValue()
+	case "ProposeSwap":
+		return p.ProposeSwap()
+	case "CompleteSwap":
+		return p.CompleteSwap()
+	case "CancelSwap":
+		return p.CancelSwap()
+	case "ReadSwap":
+		return p.ReadSwapValue()
+	case "AddOwnerGroup":
+		return p.AddOwnerGroup()
+	case "ReadOwnerGroup":
+		return p.ReadOwnerGroupValue()
+	case "HandoffCustody":
+		return p.HandoffCustody()
+	case "ReadCustody":
+		return p.ReadCustodyValue()
+	case "ReadProductHistory":
+		return p.ReadProductHistory()
+	case "Attest":
+		return p.Attest()
+	case "ReadAttestations":
+		return p.ReadAttestationsValue()
+	case "SetAttestationPolicy":
+		return p.SetAttestationPolicy()
+	case "Anchor":
+		return p.Anchor()
+	case "AnchorMerkle":
+		return p.AnchorMerkle()
+	case "ReadAnchors":
+		return p.ReadAnchorsValue()
+	case "ConfidentialTransfer":
+		return p.ConfidentialTransfer()
+	case "ReadPid":
+		return p.ReadPidValue()
+	case "AddSignGroup":
+		return p.AddSignGroup()
+	case "ReadSignGroup":
+		return p.ReadSignGroupValue()
+	case "RequestDeanonymize":
+		return p.RequestDeanonymize()
+	case "ReadDeanonLog":
+		return p.ReadDeanonLogValue()
+	case "GrantReEncryption":
+		return p.GrantReEncryption()
+	case "ReadReEncryption":
+		return p.ReadReEncryptionValue()
+	case "ReadCommitBatch":
+		return p.ReadCommitValueBatch()
 	default:
 		return sdk.Error("no function named:" + method)
 	}
@@ -147,7 +255,65 @@ func (p *OwnershipManagement) ReadOwner(tid string) (string, error) {
 }
 
 func (p *OwnershipManagement) WriteOwner(tid string, pid string) error {
-	return p.WriteState(p.BuildKey(OwnerDomain, tid), []byte(string(pid)))
+	err := p.applyReEncryption(tid, pid)
+	if err != nil {
+		return err
+	}
+	err = p.WriteState(p.BuildKey(OwnerDomain, tid), []byte(string(pid)))
+	if err != nil {
+		return err
+	}
+	return p.appendHistory(OwnerLogDomain, tid, pid)
+}
+
+// appendHistory 为tid追加一条带区块高度与交易ID的历史记录
+func (p *OwnershipManagement) appendHistory(domain, tid string, fields ...string) error {
+	height, err := sdk.Instance.GetBlockHeight()
+	if err != nil {
+		return err
+	}
+	txId, err := sdk.Instance.GetTxId()
+	if err != nil {
+		return err
+	}
+	entry := utils.EncodeStrings(append(fields, strconv.Itoa(height), txId))
+	_, err = p.AppendLog(domain, tid, entry)
+	return err
+}
+
+// AppendLog 在domain下为index追加一条只追加的记录,返回记录序号
+func (p *OwnershipManagement) AppendLog(domain, index string, entry []byte) (int, error) {
+	count, err := p.LogLength(domain, index)
+	if err != nil {
+		return 0, err
+	}
+	err = p.WriteState(p.BuildKey(domain, index+"."+strconv.Itoa(count)), entry)
+	if err != nil {
+		return 0, err
+	}
+	return count, p.WriteCounter(p.BuildKey(LogLengthDomain+domain, index), count+1)
+}
+
+// LogLength 读取domain下index的记录条数
+func (p *OwnershipManagement) LogLength(domain, index string) (int, error) {
+	return p.ReadCounter(p.BuildKey(LogLengthDomain+domain, index))
+}
+
+// ReadLog 读取domain下index的全部记录
+func (p *OwnershipManagement) ReadLog(domain, index string) ([]string, error) {
+	count, err := p.LogLength(domain, index)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]string, count)
+	for i := 0; i < count; i++ {
+		entry, err := p.ReadState(p.BuildKey(domain, index+"."+strconv.Itoa(i)))
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = string(entry)
+	}
+	return entries, nil
 }
 
 func (p *OwnershipManagement) BytesCombine(pBytes ...[]byte) []byte {
@@ -157,34 +323,75 @@ func (p *OwnershipManagement) BytesCombine(pBytes ...[]byte) []byte {
 // 智能合约方法代码
 
 // AddPid 增加一个伪ID
+// @contract_arg pid: 伪ID
+// @contract_arg pk: 伪ID对应公钥
+// @contract_arg role: 可选,伪ID的角色
+// @contract_arg escrow: 可选,身份托管记录,字符串列表编码:监管方伪ID,用监管方公钥加密的真实身份
+// @contract_arg r: 椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 椭圆曲线签名中的s，十进制整数文本形式
 func (p *OwnershipManagement) AddPid() protogo.Response {
 	pidBytes := p.ReadArgs("pid")
 	pid := string(pidBytes)
 	pk := p.ReadArgs("pk")
+	role := p.ReadArgs("role")
+	escrow := p.ReadArgs("escrow")
 	rText := p.ReadArgs("r")
 	sText := p.ReadArgs("s")
-	content := p.BytesCombine(pidBytes, pk)
+	content := p.BytesCombine(pidBytes, pk, role, escrow)
 	err := p.VerifyAdmin(content, rText, sText)
 	if err != nil {
 		return sdk.Error("permission deny:" + err.Error())
 	} else {
+		if p.HasState(p.BuildKey(GroupDomain, pid)) {
+			return sdk.Error("id already registered as owner group")
+		}
+		if p.HasState(p.BuildKey(SignGroupDomain, pid)) {
+			return sdk.Error("id already registered as sign group")
+		}
+		if strings.HasPrefix(pid, CommitOwnerPrefix) {
+			return sdk.Error("pid prefix reserved for commit owners:" + CommitOwnerPrefix)
+		}
 		err := p.WritePkByPid(pid, pk)
 		if err != nil {
 			return sdk.Error(err.Error())
 		}
+		if len(role) != 0 {
+			err = p.WriteRole(pid, string(role))
+			if err != nil {
+				return sdk.Error(err.Error())
+			}
+		}
+		if len(escrow) != 0 {
+			err = p.writeEscrow(pid, escrow)
+			if err != nil {
+				return sdk.Error(err.Error())
+			}
+		}
 		return sdk.Success([]byte("tid add success"))
 	}
 }
 
+// ReadPidValue 智能合约中的方法,查询伪ID登记的公钥,未登记时返回空
+// @contract_arg pid: 伪ID
+func (p *OwnershipManagement) ReadPidValue() protogo.Response {
+	pk, err := p.ReadPkByPid(string(p.ReadArgs("pid")))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success(pk)
+}
+
 // CreateProduct 智能合约中的方法,创建产品。
 //@contract_arg tid：产品ID
 //@contract_arg pid: 制造商的伪ID
+//@contract_arg tidPk: 可选,隐私模式下的产品密钥公钥,此时tid必须是其隐藏产品ID
 //@contract_arg r: 椭圆曲线签名中的r,十进制整数文本形式
 //@contract_arg s: 椭圆曲线签名中的s，十进制整数文本形式
 func (p *OwnershipManagement) CreateProduct() protogo.Response {
 	tid := p.ReadArgs("tid")
 	pid := p.ReadArgs("pid")
-	content := p.BytesCombine(tid, pid)
+	tidPk := p.ReadArgs("tidPk")
+	content := p.BytesCombine(tid, pid, tidPk)
 	rText := p.ReadArgs("r")
 	sText := p.ReadArgs("s")
 	err := p.VerifyAdmin(content, rText, sText)
@@ -196,6 +403,12 @@ func (p *OwnershipManagement) CreateProduct() protogo.Response {
 	if has {
 		return sdk.Error("already has product")
 	} else {
+		if len(tidPk) != 0 {
+			err := p.WriteTidPk(tidStr, tidPk)
+			if err != nil {
+				return sdk.Error(err.Error())
+			}
+		}
 		err := p.WriteOwner(tidStr, string(pid))
 		if err != nil {
 			return sdk.Error(err.Error())
@@ -244,6 +457,7 @@ func (p *OwnershipManagement) ReadCipherValueBatch() protogo.Response {
 // @contract_arg tid：标签ID
 // @contract_arg gama: alpha的密文
 // @contract_arg commit: alpha的承诺
+// @contract_arg proof: 承诺值位于[0,2^SecretBits)的范围证明,见VerifySecretRange
 // @contract_arg r: 椭圆曲线签名中的r,十进制整数文本形式
 // @contract_arg s: 椭圆曲线签名中的s，十进制整数文本形式
 func (p *OwnershipManagement) UploadAlpha() protogo.Response {
@@ -260,6 +474,10 @@ func (p *OwnershipManagement) UploadAlpha() protogo.Response {
 	if err != nil {
 		return sdk.Error(err.Error())
 	}
+	err = VerifySecretRange(commit, p.ReadArgs("proof"))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
 	err = p.WriteCipher(string(tid), true, gama)
 	if err != nil {
 		return sdk.Error(err.Error())
@@ -275,6 +493,7 @@ func (p *OwnershipManagement) UploadAlpha() protogo.Response {
 // @contract_arg tid：标签ID
 // @contract_arg gama: alpha的密文
 // @contract_arg commit: alpha的承诺
+// @contract_arg proof: 承诺值位于[0,2^SecretBits)的范围证明,见VerifySecretRange
 // @contract_arg r: 椭圆曲线签名中的r,十进制整数文本形式
 // @contract_arg s: 椭圆曲线签名中的s，十进制整数文本形式
 func (p *OwnershipManagement) UploadBeta() protogo.Response {
@@ -287,6 +506,10 @@ func (p *OwnershipManagement) UploadBeta() protogo.Response {
 	if err != nil {
 		return sdk.Error(err.Error())
 	}
+	err = VerifySecretRange(commit, p.ReadArgs("proof"))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
 	err = p.WriteCipher(string(tid), false, gama)
 	if err != nil {
 		return sdk.Error(err.Error())
@@ -329,11 +552,38 @@ func (p *OwnershipManagement) ReadCipherValue() protogo.Response {
 	return sdk.Success(buffer.Bytes())
 }
 
+// ReadCommitValueBatch 智能合约中的方法,批量查询产品alpha与beta的承诺
+// @contract_arg tid: 产品ID列表编码
+// 返回值为字符串列表编码,依次为每个产品的alpha承诺与beta承诺,未上传的一侧为空
+func (p *OwnershipManagement) ReadCommitValueBatch() protogo.Response {
+	tids, err := utils.DecodeTid(p.ReadArgs("tid"))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	commits := make([]string, 0, 2*len(tids))
+	for _, tid := range tids {
+		alpha, err := p.ReadCommit(tid, true)
+		if err != nil {
+			return sdk.Error(err.Error())
+		}
+		beta, err := p.ReadCommit(tid, false)
+		if err != nil {
+			return sdk.Error(err.Error())
+		}
+		commits = append(commits, string(alpha), string(beta))
+	}
+	return sdk.Success(utils.EncodeStrings(commits))
+}
+
 //BatchTransfer 智能合约中的方法,批量转移产品。
 //@contract_arg tid：伪ID
 //@contract_arg pid：新所有者
 //@contract_arg pSecret:聚合的秘密值
 //@contract_arg opening:聚合的盲因子
+//@contract_arg delegate: 可选,代理方的伪ID,非空时由代理方代替所有者签名并校验所有者的授权
+//@contract_arg tidSig: 隐藏产品ID的产品密钥签名集合,见VerifyTidKnowledge
+//@contract_arg rOwner: 产品当前所有者(或代理方)对同一内容的签名,所有者为共有组时为签名集合,见VerifyOwners
+//@contract_arg sOwner: 产品当前所有者签名中的s
 //@contract_arg r: 椭圆曲线签名中的r,十进制整数文本形式
 //@contract_arg s: 椭圆曲线签名中的s，十进制整数文本形式
 func (p *OwnershipManagement) BatchTransfer() protogo.Response {
@@ -341,7 +591,8 @@ func (p *OwnershipManagement) BatchTransfer() protogo.Response {
 	pid := p.ReadArgs("pid")
 	pSecret := p.ReadArgs("pSecret")
 	opening := p.ReadArgs("opening")
-	content := p.BytesCombine(pid, allTids, pSecret, opening)
+	delegate := p.ReadArgs("delegate")
+	content := p.BytesCombine(pid, allTids, pSecret, opening, delegate)
 	rText := p.ReadArgs("r")
 	sText := p.ReadArgs("s")
 	err := p.VerifyPid(string(pid), content, rText, sText)
@@ -353,49 +604,81 @@ func (p *OwnershipManagement) BatchTransfer() protogo.Response {
 	if err != nil {
 		return sdk.Error(err.Error())
 	}
+	if len(delegate) != 0 {
+		err = p.VerifyDelegate(string(delegate), tidList, content, p.ReadArgs("rOwner"), p.ReadArgs("sOwner"))
+	} else {
+		err = p.VerifyOwners(tidList, content, p.ReadArgs("rOwner"), p.ReadArgs("sOwner"))
+	}
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.VerifyTidKnowledge(tidList, content, p.ReadArgs("tidSig"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.VerifyBatchSecret(tidList, pSecret, opening)
+	if err != nil {
+		return sdk.Error("permission deny when batch transfer product:" + err.Error())
+	}
+	err = p.CheckTransferPolicy(tidList, string(pid))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.CheckAttestations(tidList)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	for i := 0; i < len(tidList); i++ {
+		tid := tidList[i]
+		err := p.WriteOwner(tid, string(pid))
+		if err != nil {
+			return sdk.Error(err.Error())
+		}
+	}
+	return sdk.Success([]byte("transfer product success"))
+}
+
+// VerifyBatchSecret 校验聚合的秘密值与盲因子是否打开tidList中全部alpha与beta承诺之和
+func (p *OwnershipManagement) VerifyBatchSecret(tidList []string, pSecret, opening []byte) error {
 	length := len(tidList)
 	openings := make([]byte, 32)
 	commits, err := bulletproofs.PedersenCommitSpecificOpening(0, openings)
 	if err != nil {
-		return sdk.Error(err.Error())
+		return err
 	}
 	for i := 0; i < length; i++ {
 		tid := tidList[i]
 		commitAlpha, err := p.ReadCommit(tid, true)
 		if err != nil {
-			return sdk.Error(err.Error())
+			return err
 		}
 		commitBeta, err := p.ReadCommit(tid, false)
 		if err != nil {
-			return sdk.Error(err.Error())
+			return err
 		}
 		tempCommitAd, err := bulletproofs.PedersenAddCommitment(commitAlpha, commitBeta)
 		if err != nil {
-			return sdk.Error(err.Error())
+			return err
 		}
 		tempCommit, err := bulletproofs.PedersenAddCommitment(tempCommitAd, commits)
 		if err != nil {
-			return sdk.Error(err.Error())
+			return err
 		}
 		commits = tempCommit
 	}
 	u := utils.BytesToUint64(pSecret)
 	addOpening, err := bulletproofs.PedersenAddOpening(opening, openings)
 	if err != nil {
-		return sdk.Error(err.Error())
+		return err
 	}
-	res, _ := bulletproofs.PedersenVerify(commits, addOpening, u)
-	if !res {
-		sdk.Error("permission deny when batch transfer product:commit not match")
+	res, err := bulletproofs.PedersenVerify(commits, addOpening, u)
+	if err != nil {
+		return err
 	}
-	for i := 0; i < length; i++ {
-		tid := tidList[i]
-		err := p.WriteOwner(tid, string(pid))
-		if err != nil {
-			return sdk.Error(err.Error())
-		}
+	if !res {
+		return fmt.Errorf("commit not match")
 	}
-	return sdk.Success([]byte("transfer product success"))
+	return nil
 }
 
 func (p *OwnershipManagement) HasProduct(tid string) bool {
@@ -415,7 +698,22 @@ func (p *OwnershipManagement) VerifyAdmin(content, rText, sText []byte) error {
 	return ecdsa_pid.VerifySign(pkBytes, content, rText, sText)
 }
 
+// VerifyPid 校验pid对content的签名,pid为共有组时rText为签名集合
 func (p *OwnershipManagement) VerifyPid(pid string, content, rText, sText []byte) error {
+	group, err := p.ReadOwnerGroup(pid)
+	if err != nil {
+		return err
+	}
+	if group != nil {
+		return p.VerifyGroup(group, content, rText)
+	}
+	signGroup, err := p.ReadSignGroup(pid)
+	if err != nil {
+		return err
+	}
+	if signGroup != nil {
+		return p.VerifySignGroup(signGroup, content, rText)
+	}
 	pkBytes, err := p.ReadPkByPid(pid)
 	if err != nil {
 		return err
@@ -423,6 +721,26 @@ func (p *OwnershipManagement) VerifyPid(pid string, content, rText, sText []byte
 	return ecdsa_pid.VerifySign(pkBytes, content, rText, sText)
 }
 
+// VerifyOwners 校验tidList中的产品属于同一当前所有者,并校验该所有者对content的签名
+// 所有者为共有组时rText为签名集合,需满足组的门限
+func (p *OwnershipManagement) VerifyOwners(tidList []string, content, rText, sText []byte) error {
+	if len(tidList) == 0 {
+		return fmt.Errorf("empty tid list")
+	}
+	owner, err := p.ReadOwner(tidList[0])
+	if err != nil {
+		return err
+	}
+	if len(owner) == 0 {
+		return fmt.Errorf("product not exist:%s", tidList[0])
+	}
+	err = p.verifyOwnerOf(owner, tidList)
+	if err != nil {
+		return err
+	}
+	return p.VerifyPid(owner, content, rText, sText)
+}
+
 func main() {
 	err := sandbox.Start(new(OwnershipManagement))
 	if err != nil {
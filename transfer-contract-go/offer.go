@@ -0,0 +1,266 @@
+package main
+
+import (
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"fmt"
+	"strconv"
+	"transfer-contract-go/utils"
+)
+
+// 两阶段转移相关代码
+const (
+	OfferDomain = "offer."
+)
+
+// TransferOffer 当前所有者发起、等待接收方确认的批量转移
+type TransferOffer struct {
+	Owner        string
+	Pid          string
+	Tids         []string
+	ExpireHeight int
+	ExpireTime   int64
+}
+
+func (p *OwnershipManagement) ReadOffer(offerId string) (*TransferOffer, error) {
+	record, err := p.ReadState(p.BuildKey(OfferDomain, offerId))
+	if err != nil {
+		return nil, err
+	}
+	if len(record) == 0 {
+		return nil, fmt.Errorf("no offer:%s", offerId)
+	}
+	fields, err := utils.DecodeStrings(record)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid offer record")
+	}
+	offer := &TransferOffer{Owner: fields[0], Pid: fields[1]}
+	offer.Tids, err = utils.DecodeTid([]byte(fields[2]))
+	if err != nil {
+		return nil, err
+	}
+	offer.ExpireHeight, err = parseOptionalInt(fields[3])
+	if err != nil {
+		return nil, err
+	}
+	expireTime, err := parseOptionalInt(fields[4])
+	if err != nil {
+		return nil, err
+	}
+	offer.ExpireTime = int64(expireTime)
+	return offer, nil
+}
+
+// Expired 判断报价在当前交易的区块高度与时间戳下是否已过期,0表示不限制
+func (o *TransferOffer) Expired() (bool, error) {
+	if o.ExpireHeight > 0 {
+		height, err := sdk.Instance.GetBlockHeight()
+		if err != nil {
+			return false, err
+		}
+		if height > o.ExpireHeight {
+			return true, nil
+		}
+	}
+	if o.ExpireTime > 0 {
+		timestamp, err := sdk.Instance.GetTxTimeStamp()
+		if err != nil {
+			return false, err
+		}
+		now, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		if now > o.ExpireTime {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func parseOptionalInt(text string) (int, error) {
+	if text == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(text)
+}
+
+// OfferTransfer 智能合约中的方法,当前所有者向接收方发起批量转移
+// 报价ID为本交易的交易ID
+// @contract_arg owner: 当前所有者的伪ID
+// @contract_arg tid: 产品ID列表编码
+// @contract_arg pid: 接收方的伪ID
+// @contract_arg expireHeight: 可选,报价失效的区块高度
+// @contract_arg expireTime: 可选,报价失效的时间戳(秒)
+// @contract_arg tidSig: 隐藏产品ID的产品密钥签名集合,见VerifyTidKnowledge
+// @contract_arg r: 所有者椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 所有者椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) OfferTransfer() protogo.Response {
+	owner := p.ReadArgs("owner")
+	allTids := p.ReadArgs("tid")
+	pid := p.ReadArgs("pid")
+	expireHeight := p.ReadArgs("expireHeight")
+	expireTime := p.ReadArgs("expireTime")
+	rText := p.ReadArgs("r")
+	sText := p.ReadArgs("s")
+	content := p.BytesCombine([]byte("OfferTransfer"), owner, allTids, pid, expireHeight, expireTime)
+	err := p.VerifyPid(string(owner), content, rText, sText)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	if !p.IsRegistered(string(pid)) {
+		return sdk.Error("recipient pid not registered")
+	}
+	if _, err = parseOptionalInt(string(expireHeight)); err != nil {
+		return sdk.Error(err.Error())
+	}
+	if _, err = parseOptionalInt(string(expireTime)); err != nil {
+		return sdk.Error(err.Error())
+	}
+	tidList, err := utils.DecodeTid(allTids)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	err = p.verifyOwnerOf(string(owner), tidList)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.VerifyTidKnowledge(tidList, content, p.ReadArgs("tidSig"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	offerId, err := sdk.Instance.GetTxId()
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	record := utils.EncodeStrings([]string{string(owner), string(pid), string(allTids), string(expireHeight), string(expireTime)})
+	err = p.WriteState(p.BuildKey(OfferDomain, offerId), record)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	sdk.Instance.EmitEvent("OfferTransfer", []string{offerId, string(owner), string(pid)})
+	return sdk.Success([]byte(offerId))
+}
+
+// AcceptTransfer 智能合约中的方法,接收方确认报价并完成转移
+// 秘密值在确认时才提交,提交的同一交易中所有权即已转移
+// @contract_arg offer: 报价ID
+// @contract_arg pSecret: 聚合的秘密值
+// @contract_arg opening: 聚合的盲因子
+// @contract_arg r: 接收方椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 接收方椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) AcceptTransfer() protogo.Response {
+	offerId := p.ReadArgs("offer")
+	pSecret := p.ReadArgs("pSecret")
+	opening := p.ReadArgs("opening")
+	offer, err := p.ReadOffer(string(offerId))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	err = p.VerifyPid(offer.Pid, p.BytesCombine([]byte("AcceptTransfer"), offerId, pSecret, opening), p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	expired, err := offer.Expired()
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if expired {
+		return sdk.Error("offer expired")
+	}
+	err = p.verifyOwnerOf(offer.Owner, offer.Tids)
+	if err != nil {
+		return sdk.Error("offer outdated:" + err.Error())
+	}
+	err = p.VerifyBatchSecret(offer.Tids, pSecret, opening)
+	if err != nil {
+		return sdk.Error("permission deny when accept transfer:" + err.Error())
+	}
+	err = p.CheckTransferPolicy(offer.Tids, offer.Pid)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.CheckAttestations(offer.Tids)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	for _, tid := range offer.Tids {
+		err := p.WriteOwner(tid, offer.Pid)
+		if err != nil {
+			return sdk.Error(err.Error())
+		}
+	}
+	err = p.DeleteState(p.BuildKey(OfferDomain, string(offerId)))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("accept transfer success"))
+}
+
+// RejectTransfer 智能合约中的方法,接收方拒绝报价
+// @contract_arg offer: 报价ID
+// @contract_arg r: 接收方椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 接收方椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) RejectTransfer() protogo.Response {
+	return p.closeOffer("RejectTransfer", false)
+}
+
+// CancelTransfer 智能合约中的方法,所有者撤回报价
+// @contract_arg offer: 报价ID
+// @contract_arg r: 所有者椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 所有者椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) CancelTransfer() protogo.Response {
+	return p.closeOffer("CancelTransfer", true)
+}
+
+func (p *OwnershipManagement) closeOffer(action string, byOwner bool) protogo.Response {
+	offerId := p.ReadArgs("offer")
+	offer, err := p.ReadOffer(string(offerId))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	signer := offer.Pid
+	if byOwner {
+		signer = offer.Owner
+	}
+	err = p.VerifyPid(signer, p.BytesCombine([]byte(action), offerId), p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.DeleteState(p.BuildKey(OfferDomain, string(offerId)))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte(action + " success"))
+}
+
+// ReadOfferValue 智能合约中的方法,查询报价
+// @contract_arg offer: 报价ID
+// 返回值为字符串列表编码:所有者,接收方,产品ID列表编码,失效区块高度,失效时间戳
+func (p *OwnershipManagement) ReadOfferValue() protogo.Response {
+	record, err := p.ReadState(p.BuildKey(OfferDomain, string(p.ReadArgs("offer"))))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if len(record) == 0 {
+		return sdk.Error("no offer")
+	}
+	return sdk.Success(record)
+}
+
+// verifyOwnerOf 校验tidList中每个产品的当前所有者均为owner
+func (p *OwnershipManagement) verifyOwnerOf(owner string, tidList []string) error {
+	for _, tid := range tidList {
+		current, err := p.ReadOwner(tid)
+		if err != nil {
+			return err
+		}
+		if current != owner {
+			return fmt.Errorf("tid %s not owned by %s", tid, owner)
+		}
+	}
+	return nil
+}
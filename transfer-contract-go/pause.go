@@ -0,0 +1,115 @@
+package main
+
+import (
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"strconv"
+	"transfer-contract-go/utils"
+)
+
+// 暂停开关相关代码
+const (
+	PauseDomain    = "pause."
+	PauseLogDomain = "pauselog."
+	PauseAll       = "all"
+)
+
+// readOnlyMethods 暂停期间仍然允许调用的只读方法
+var readOnlyMethods = map[string]bool{
+	"ReadCipher":         true,
+	"ReadCipherBatch":    true,
+	"f":                  true,
+	"ReadPauseLog":       true,
+	"ReadGrant":          true,
+	"ReadRole":           true,
+	"ReadTransferPolicy": true,
+	"ReadOffer":          true,
+	"ReadSwap":           true,
+	"ReadOwnerGroup":     true,
+	"ReadCustody":        true,
+	"ReadProductHistory": true,
+	"ReadAttestations":   true,
+	"ReadAnchors":        true,
+	"ReadPid":            true,
+	"ReadDeanonLog":      true,
+	"ReadSignGroup":      true,
+	"ReadReEncryption":   true,
+	"ReadCommitBatch":    true,
+}
+
+// pauseControlMethods 暂停期间仍然允许调用的管理方法
+var pauseControlMethods = map[string]bool{
+	"Pause":   true,
+	"Unpause": true,
+}
+
+// IsPaused 判断方法在当前是否被暂停,只读方法与暂停控制方法永远不会被暂停
+func (p *OwnershipManagement) IsPaused(method string) bool {
+	if readOnlyMethods[method] || pauseControlMethods[method] {
+		return false
+	}
+	return p.HasState(p.BuildKey(PauseDomain, PauseAll)) || p.HasState(p.BuildKey(PauseDomain, method))
+}
+
+// Pause 智能合约中的方法,管理员暂停合约中的状态修改方法
+// @contract_arg method: 需要暂停的方法名,为空表示暂停全部状态修改方法
+// @contract_arg seq: 暂停日志当前长度,十进制整数文本形式,用于防止签名重放
+// @contract_arg r: 椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) Pause() protogo.Response {
+	return p.switchPause("Pause", true)
+}
+
+// Unpause 智能合约中的方法,管理员恢复被暂停的方法
+// @contract_arg method: 需要恢复的方法名,为空表示解除全局暂停
+// @contract_arg seq: 暂停日志当前长度,十进制整数文本形式,用于防止签名重放
+// @contract_arg r: 椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) Unpause() protogo.Response {
+	return p.switchPause("Unpause", false)
+}
+
+func (p *OwnershipManagement) switchPause(action string, pause bool) protogo.Response {
+	method := p.ReadArgs("method")
+	seq := p.ReadArgs("seq")
+	rText := p.ReadArgs("r")
+	sText := p.ReadArgs("s")
+	err := p.VerifyAdmin(p.BytesCombine([]byte(action), method, seq), rText, sText)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	count, err := p.LogLength(PauseLogDomain, PauseAll)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if string(seq) != strconv.Itoa(count) {
+		return sdk.Error("pause seq not match, expect:" + strconv.Itoa(count))
+	}
+	scope := string(method)
+	if scope == "" {
+		scope = PauseAll
+	}
+	if pause {
+		err = p.WriteState(p.BuildKey(PauseDomain, scope), []byte("1"))
+	} else {
+		err = p.DeleteState(p.BuildKey(PauseDomain, scope))
+	}
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	err = p.appendHistory(PauseLogDomain, PauseAll, action, scope)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte(action + " " + scope + " success"))
+}
+
+// ReadPauseLog 智能合约中的方法,读取全部暂停/恢复记录
+// 返回值为字符串列表编码,每一项为一条记录,记录本身也是字符串列表编码:动作,方法,区块高度,交易ID
+func (p *OwnershipManagement) ReadPauseLog() protogo.Response {
+	entries, err := p.ReadLog(PauseLogDomain, PauseAll)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success(utils.EncodeStrings(entries))
+}
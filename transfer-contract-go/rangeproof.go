@@ -0,0 +1,48 @@
+package main
+
+import (
+	"chainmaker.org/chainmaker/common/v2/crypto/bulletproofs"
+	"fmt"
+	"transfer-contract-go/utils"
+)
+
+// 秘密值范围证明相关代码
+// bulletproofs的范围证明固定为[0,2^64)。上传方同时证明x与x+2^64-2^SecretBits都在[0,2^64)内,
+// 第二个承诺由合约从原承诺加上常数得到,两者合起来说明x位于[0,2^SecretBits)。
+// SecretBits取40,保证一次批量转移中alpha与beta的总和不会溢出uint64。
+const (
+	SecretBits = 40
+	// rangeOffset 2^64-2^SecretBits
+	rangeOffset uint64 = 1<<64 - 1<<SecretBits
+)
+
+// VerifySecretRange 校验承诺的值位于[0,2^SecretBits)
+// proof为字符串列表编码:x的范围证明,x+2^64-2^SecretBits的范围证明
+func VerifySecretRange(commit, proof []byte) error {
+	proofs, err := utils.DecodeStrings(proof)
+	if err != nil {
+		return err
+	}
+	if len(proofs) != 2 {
+		return fmt.Errorf("range proof required")
+	}
+	ok, err := bulletproofs.Verify([]byte(proofs[0]), commit)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("range proof verification failed")
+	}
+	shifted, err := bulletproofs.PedersenAddNum(commit, rangeOffset)
+	if err != nil {
+		return err
+	}
+	ok, err = bulletproofs.Verify([]byte(proofs[1]), shifted)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("secret out of range")
+	}
+	return nil
+}
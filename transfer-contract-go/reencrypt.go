@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"crypto/elliptic"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"transfer-contract-go/utils"
+)
+
+// 代理重加密相关代码
+// 所有者以自己伪ID的公钥按代理重加密方案上传alpha后,可以为下一任所有者登记重加密密钥。
+// 所有权转移给该伪ID时,合约用重加密密钥变换cipher.al.<tid>的密钥部分,新所有者即可用自己的私钥解密,
+// 原所有者无需在线,合约也得不到对称密钥与alpha。
+// 该方案的密钥部分为 E(65字节)||掩码后的对称密钥(16字节)[||X(65字节)],重加密把E替换为rk*E并写入X。
+// rk只由所有者对该gama的一次性有效标量生成,公开的rk与X不会暴露所有者的伪ID私钥。
+const (
+	ReKeyDomain = "rekey."
+	SchemePRE   = 2
+
+	gamaMagic       = "\xffGM"
+	gamaV1          = 1
+	gamaHeader      = len(gamaMagic) + 3 + 4
+	prePointSize    = 65
+	preKeyPartSize  = prePointSize + 16
+	preReKeyedSize  = preKeyPartSize + prePointSize
+	reKeyFieldCount = 4
+)
+
+// ReEncryption 所有者登记的重加密密钥
+type ReEncryption struct {
+	To  string
+	Key []byte
+	X   []byte
+	// Seq 登记时产品所有权历史的条数,所有权变化后登记失效
+	Seq int
+}
+
+// ReadReEncryption 读取产品登记的重加密密钥,不存在时返回nil
+func (p *OwnershipManagement) ReadReEncryption(tid string) (*ReEncryption, error) {
+	record, err := p.ReadState(p.BuildKey(ReKeyDomain, tid))
+	if err != nil {
+		return nil, err
+	}
+	if len(record) == 0 {
+		return nil, nil
+	}
+	fields, err := utils.DecodeStrings(record)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != reKeyFieldCount {
+		return nil, fmt.Errorf("invalid rekey record")
+	}
+	seq, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return nil, err
+	}
+	return &ReEncryption{To: fields[0], Key: []byte(fields[1]), X: []byte(fields[2]), Seq: seq}, nil
+}
+
+// GrantReEncryption 智能合约中的方法,所有者为下一任所有者登记alpha的重加密密钥
+// @contract_arg tid: 产品ID
+// @contract_arg to: 下一任所有者的伪ID,产品转移给该伪ID时重加密alpha
+// @contract_arg rk: 重加密密钥,32字节大端整数
+// @contract_arg x: 重加密附带的公开点,65字节非压缩格式
+// @contract_arg seq: 产品所有权历史的条数,十进制整数文本形式,所有权变化后登记失效
+// @contract_arg r: 所有者椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 所有者椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) GrantReEncryption() protogo.Response {
+	tid := p.ReadArgs("tid")
+	to := p.ReadArgs("to")
+	rk := p.ReadArgs("rk")
+	x := p.ReadArgs("x")
+	seq := p.ReadArgs("seq")
+	owner, err := p.ReadOwner(string(tid))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if len(owner) == 0 {
+		return sdk.Error("product not exist:" + string(tid))
+	}
+	content := p.BytesCombine([]byte("GrantReEncryption"), tid, to, rk, x, seq)
+	err = p.VerifyPid(owner, content, p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	count, err := p.LogLength(OwnerLogDomain, string(tid))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if string(seq) != strconv.Itoa(count) {
+		return sdk.Error("rekey seq not match, expect:" + strconv.Itoa(count))
+	}
+	if !p.IsRegistered(string(to)) || string(to) == owner {
+		return sdk.Error("invalid rekey target:" + string(to))
+	}
+	if !validScalar(rk) {
+		return sdk.Error("invalid rekey")
+	}
+	if _, _, err := unmarshalPoint(x); err != nil {
+		return sdk.Error(err.Error())
+	}
+	gama, err := p.ReadCipher(string(tid), true)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if !isPreGama(gama) {
+		return sdk.Error("alpha is not re-encryptable:" + string(tid))
+	}
+	record := utils.EncodeStrings([]string{string(to), string(rk), string(x), string(seq)})
+	err = p.WriteState(p.BuildKey(ReKeyDomain, string(tid)), record)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("grant re-encryption success"))
+}
+
+// ReadReEncryptionValue 智能合约中的方法,查询产品登记的重加密密钥
+// @contract_arg tid: 产品ID
+// 返回值为字符串列表编码:接收方伪ID,rk,X,登记时的所有权历史条数;未登记时为空
+func (p *OwnershipManagement) ReadReEncryptionValue() protogo.Response {
+	record, err := p.ReadState(p.BuildKey(ReKeyDomain, string(p.ReadArgs("tid"))))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success(record)
+}
+
+// applyReEncryption 所有权变更前调用,登记的接收方为newOwner时重加密alpha
+// 无论是否匹配,登记都只能使用一次
+func (p *OwnershipManagement) applyReEncryption(tid, newOwner string) error {
+	rekey, err := p.ReadReEncryption(tid)
+	if err != nil || rekey == nil {
+		return err
+	}
+	err = p.DeleteState(p.BuildKey(ReKeyDomain, tid))
+	if err != nil {
+		return err
+	}
+	count, err := p.LogLength(OwnerLogDomain, tid)
+	if err != nil {
+		return err
+	}
+	if rekey.Seq != count || rekey.To != newOwner {
+		return nil
+	}
+	gama, err := p.ReadCipher(tid, true)
+	if err != nil {
+		return err
+	}
+	if !isPreGama(gama) {
+		return nil
+	}
+	gama, err = reEncryptGama(gama, rekey.Key, rekey.X)
+	if err != nil {
+		return err
+	}
+	err = p.WriteCipher(tid, true, gama)
+	if err != nil {
+		return err
+	}
+	sdk.Instance.EmitEvent("ReEncryptAlpha", []string{tid, newOwner})
+	return nil
+}
+
+// isPreGama 判断gama是否为代理重加密方案的v1格式
+func isPreGama(gama []byte) bool {
+	return len(gama) > gamaHeader && string(gama[:len(gamaMagic)]) == gamaMagic &&
+		gama[len(gamaMagic)] == gamaV1 && gama[len(gamaMagic)+2] == SchemePRE
+}
+
+// reEncryptGama 把密钥部分中的E替换为rk*E并附上X,头部与密文保持不变
+func reEncryptGama(gama, rk, x []byte) ([]byte, error) {
+	if len(gama) < gamaHeader+4 {
+		return nil, fmt.Errorf("gama truncated")
+	}
+	size := int(binary.BigEndian.Uint32(gama[gamaHeader:]))
+	start := gamaHeader + 4
+	if size < 0 || len(gama)-start < size {
+		return nil, fmt.Errorf("gama truncated")
+	}
+	keyPart := gama[start : start+size]
+	if size != preKeyPartSize && size != preReKeyedSize {
+		return nil, fmt.Errorf("invalid re-encryptable key part size:%d", size)
+	}
+	ex, ey, err := unmarshalPoint(keyPart[:prePointSize])
+	if err != nil {
+		return nil, err
+	}
+	curve := elliptic.P256()
+	rx, ry := curve.ScalarMult(ex, ey, rk)
+
+	buffer := bytes.NewBuffer(append([]byte{}, gama[:gamaHeader]...))
+	_ = binary.Write(buffer, binary.BigEndian, int32(preReKeyedSize))
+	buffer.Write(elliptic.Marshal(curve, rx, ry))
+	buffer.Write(keyPart[prePointSize:preKeyPartSize])
+	buffer.Write(x)
+	buffer.Write(gama[start+size:])
+	return buffer.Bytes(), nil
+}
+
+func unmarshalPoint(point []byte) (*big.Int, *big.Int, error) {
+	x, y := elliptic.Unmarshal(elliptic.P256(), point)
+	if x == nil {
+		return nil, nil, fmt.Errorf("invalid curve point")
+	}
+	return x, y, nil
+}
+
+// validScalar 判断k是否为[1,N)内的32字节标量
+func validScalar(k []byte) bool {
+	if len(k) != 32 {
+		return false
+	}
+	v := new(big.Int).SetBytes(k)
+	return v.Sign() > 0 && v.Cmp(elliptic.P256().Params().N) < 0
+}
@@ -0,0 +1,92 @@
+package ringsig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"transfer-contract-go/utils"
+)
+
+// 可打开的环签名验证
+// 签名编码为字符串列表:C1,C2,c0,z_0..z_{n-1},w_0..w_{n-1},其中(C1,C2)是签名者公钥在管理方公钥下的
+// ElGamal密文。挑战值计算需与客户端transfer-client-go/ringsig保持一致。
+
+const label = "BPOTS-ring"
+
+var curve = elliptic.P256()
+
+// Verify 验证签名来自环中某个成员
+func Verify(message []byte, ring []*ecdsa.PublicKey, manager *ecdsa.PublicKey, sig []byte) error {
+	n := len(ring)
+	if n == 0 {
+		return fmt.Errorf("empty ring")
+	}
+	fields, err := utils.DecodeStrings(sig)
+	if err != nil {
+		return err
+	}
+	if len(fields) != 3+2*n {
+		return fmt.Errorf("ring signature size not match")
+	}
+	c1x, c1y := elliptic.Unmarshal(curve, []byte(fields[0]))
+	c2x, c2y := elliptic.Unmarshal(curve, []byte(fields[1]))
+	if c1x == nil || c2x == nil {
+		return fmt.Errorf("invalid ring signature point")
+	}
+	prefix := challengePrefix(message, ring, manager, c1x, c1y, c2x, c2y)
+	c0 := new(big.Int).SetBytes([]byte(fields[2]))
+	c := c0
+	for i := 0; i < n; i++ {
+		z := new(big.Int).SetBytes([]byte(fields[3+i]))
+		w := new(big.Int).SetBytes([]byte(fields[3+n+i]))
+		c = step(prefix, ring[i], manager, c1x, c1y, c2x, c2y, c, z, w)
+	}
+	if c.Cmp(c0) != 0 {
+		return fmt.Errorf("ring signature verification failed")
+	}
+	return nil
+}
+
+// step 由第i个成员的响应值计算下一个挑战值
+func step(prefix []byte, pk, manager *ecdsa.PublicKey, c1x, c1y, c2x, c2y, c, z, w *big.Int) *big.Int {
+	ax, ay := linear(curve.Params().Gx, curve.Params().Gy, z, pk.X, pk.Y, c)
+	b1x, b1y := linear(curve.Params().Gx, curve.Params().Gy, w, c1x, c1y, c)
+	dx, dy := curve.Add(c2x, c2y, pk.X, new(big.Int).Sub(curve.Params().P, pk.Y))
+	b2x, b2y := linear(manager.X, manager.Y, w, dx, dy, c)
+	return challenge(prefix, ax, ay, b1x, b1y, b2x, b2y)
+}
+
+// linear 计算u*P+v*Q
+func linear(px, py, u, qx, qy, v *big.Int) (*big.Int, *big.Int) {
+	x1, y1 := curve.ScalarMult(px, py, u.Bytes())
+	x2, y2 := curve.ScalarMult(qx, qy, v.Bytes())
+	return curve.Add(x1, y1, x2, y2)
+}
+
+func challengePrefix(message []byte, ring []*ecdsa.PublicKey, manager *ecdsa.PublicKey, c1x, c1y, c2x, c2y *big.Int) []byte {
+	var buffer []byte
+	buffer = append(buffer, label...)
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(message)))
+	buffer = append(buffer, size[:]...)
+	buffer = append(buffer, message...)
+	buffer = append(buffer, elliptic.Marshal(curve, manager.X, manager.Y)...)
+	for _, pk := range ring {
+		buffer = append(buffer, elliptic.Marshal(curve, pk.X, pk.Y)...)
+	}
+	buffer = append(buffer, elliptic.Marshal(curve, c1x, c1y)...)
+	return append(buffer, elliptic.Marshal(curve, c2x, c2y)...)
+}
+
+func challenge(prefix []byte, ax, ay, b1x, b1y, b2x, b2y *big.Int) *big.Int {
+	hash := sha256.New()
+	hash.Write(prefix)
+	hash.Write(elliptic.Marshal(curve, ax, ay))
+	hash.Write(elliptic.Marshal(curve, b1x, b1y))
+	hash.Write(elliptic.Marshal(curve, b2x, b2y))
+	c := new(big.Int).SetBytes(hash.Sum(nil))
+	return c.Mod(c, curve.Params().N)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"fmt"
+	"strconv"
+	"strings"
+	"transfer-contract-go/utils"
+)
+
+// 角色与转移策略相关代码
+const (
+	RoleDomain      = "role."
+	TransferPolicy  = "policy.table"
+	AdminSeq        = "adminseq"
+	PolicyEnabled   = "1"
+	TransitionSplit = ">"
+)
+
+// ReadRole 读取pid的角色,未登记时返回空字符串
+func (p *OwnershipManagement) ReadRole(pid string) (string, error) {
+	role, err := p.ReadState(p.BuildKey(RoleDomain, pid))
+	if err != nil {
+		return "", err
+	}
+	return string(role), nil
+}
+
+func (p *OwnershipManagement) WriteRole(pid string, role string) error {
+	if strings.Contains(role, TransitionSplit) {
+		return fmt.Errorf("invalid role:%s", role)
+	}
+	return p.WriteState(p.BuildKey(RoleDomain, pid), []byte(role))
+}
+
+// checkAdminSeq 校验并递增管理员操作序号,防止管理员签名被重放
+func (p *OwnershipManagement) checkAdminSeq(seq []byte) error {
+	count, err := p.ReadCounter(AdminSeq)
+	if err != nil {
+		return err
+	}
+	if string(seq) != strconv.Itoa(count) {
+		return fmt.Errorf("admin seq not match, expect:%d", count)
+	}
+	return p.WriteCounter(AdminSeq, count+1)
+}
+
+// SetRole 智能合约中的方法,管理员为已登记的伪ID设置角色
+// @contract_arg pid: 伪ID
+// @contract_arg role: 角色,例如manufacturer,distributor,carrier,retailer,consumer
+// @contract_arg seq: 当前管理员操作序号,十进制整数文本形式
+// @contract_arg r: 椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) SetRole() protogo.Response {
+	pid := p.ReadArgs("pid")
+	role := p.ReadArgs("role")
+	seq := p.ReadArgs("seq")
+	rText := p.ReadArgs("r")
+	sText := p.ReadArgs("s")
+	err := p.VerifyAdmin(p.BytesCombine([]byte("SetRole"), pid, role, seq), rText, sText)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	if !p.IsRegistered(string(pid)) {
+		return sdk.Error("pid not registered")
+	}
+	err = p.checkAdminSeq(seq)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	err = p.WriteRole(string(pid), string(role))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("set role success"))
+}
+
+// SetTransferPolicy 智能合约中的方法,管理员整体替换角色转移策略表
+// @contract_arg policy: 允许的角色转移列表编码,每一项形如"manufacturer>distributor"
+// @contract_arg enabled: 为"1"时BatchTransfer强制执行策略
+// @contract_arg seq: 当前管理员操作序号,十进制整数文本形式
+// @contract_arg r: 椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) SetTransferPolicy() protogo.Response {
+	policy := p.ReadArgs("policy")
+	enabled := p.ReadArgs("enabled")
+	seq := p.ReadArgs("seq")
+	rText := p.ReadArgs("r")
+	sText := p.ReadArgs("s")
+	err := p.VerifyAdmin(p.BytesCombine([]byte("SetTransferPolicy"), policy, enabled, seq), rText, sText)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	transitions, err := utils.DecodeStrings(policy)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	for _, transition := range transitions {
+		if strings.Count(transition, TransitionSplit) != 1 {
+			return sdk.Error("invalid transition:" + transition)
+		}
+	}
+	err = p.checkAdminSeq(seq)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	err = p.WriteState(TransferPolicy, utils.EncodeStrings([]string{string(enabled), string(policy)}))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("set transfer policy success"))
+}
+
+// ReadTransferPolicyValue 智能合约中的方法,查询角色转移策略
+// 返回值为字符串列表编码:当前管理员操作序号,是否启用,允许的角色转移列表编码
+func (p *OwnershipManagement) ReadTransferPolicyValue() protogo.Response {
+	seq, err := p.ReadCounter(AdminSeq)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	enabled, transitions, err := p.readTransferPolicy()
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	flag := ""
+	if enabled {
+		flag = PolicyEnabled
+	}
+	return sdk.Success(utils.EncodeStrings([]string{strconv.Itoa(seq), flag, string(utils.EncodeStrings(transitions))}))
+}
+
+// ReadRoleValue 智能合约中的方法,查询伪ID的角色
+// @contract_arg pid: 伪ID
+func (p *OwnershipManagement) ReadRoleValue() protogo.Response {
+	role, err := p.ReadRole(string(p.ReadArgs("pid")))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte(role))
+}
+
+func (p *OwnershipManagement) readTransferPolicy() (bool, []string, error) {
+	record, err := p.ReadState(TransferPolicy)
+	if err != nil {
+		return false, nil, err
+	}
+	if len(record) == 0 {
+		return false, nil, nil
+	}
+	fields, err := utils.DecodeStrings(record)
+	if err != nil {
+		return false, nil, err
+	}
+	if len(fields) != 2 {
+		return false, nil, fmt.Errorf("invalid transfer policy record")
+	}
+	transitions, err := utils.DecodeStrings([]byte(fields[1]))
+	if err != nil {
+		return false, nil, err
+	}
+	return fields[0] == PolicyEnabled, transitions, nil
+}
+
+// CheckTransferPolicy 策略启用时,校验每个产品当前所有者的角色允许转移给pid的角色
+// 承诺所有者(见ConfidentialTransfer)没有角色,转入或转出承诺所有者的产品不受该策略约束
+func (p *OwnershipManagement) CheckTransferPolicy(tidList []string, pid string) error {
+	enabled, transitions, err := p.readTransferPolicy()
+	if err != nil || !enabled {
+		return err
+	}
+	if strings.HasPrefix(pid, CommitOwnerPrefix) {
+		return nil
+	}
+	allowed := make(map[string]bool, len(transitions))
+	for _, transition := range transitions {
+		allowed[transition] = true
+	}
+	toRole, err := p.ReadRole(pid)
+	if err != nil {
+		return err
+	}
+	if toRole == "" {
+		return fmt.Errorf("pid has no role:%s", pid)
+	}
+	for _, tid := range tidList {
+		owner, err := p.ReadOwner(tid)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(owner, CommitOwnerPrefix) {
+			continue
+		}
+		fromRole, err := p.ReadRole(owner)
+		if err != nil {
+			return err
+		}
+		if fromRole == "" {
+			return fmt.Errorf("owner of tid %s has no role", tid)
+		}
+		if !allowed[fromRole+TransitionSplit+toRole] {
+			return fmt.Errorf("transfer from %s to %s not allowed for tid:%s", fromRole, toRole, tid)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"crypto/ecdsa"
+	"fmt"
+	"strings"
+	"transfer-contract-go/ecdsa_pid"
+	"transfer-contract-go/ringsig"
+	"transfer-contract-go/utils"
+)
+
+// 签名组相关代码
+// 签名组的组ID与伪ID共用命名空间,任一成员都可以代表组签名,合约只验证签名来自某个成员而不知道是哪一个。
+// 以组ID签名时,参数r为环签名编码(见ringsig包),参数s为空。签名中包含签名者公钥在管理方公钥下的密文,
+// 管理方需要时可以打开签名确定签名成员。
+const (
+	SignGroupDomain = "signgroup."
+)
+
+// SignGroup 签名组
+type SignGroup struct {
+	Manager string
+	Members []string
+}
+
+// ReadSignGroup 读取签名组,gid不是签名组时返回nil
+func (p *OwnershipManagement) ReadSignGroup(gid string) (*SignGroup, error) {
+	record, err := p.ReadState(p.BuildKey(SignGroupDomain, gid))
+	if err != nil {
+		return nil, err
+	}
+	if len(record) == 0 {
+		return nil, nil
+	}
+	fields, err := utils.DecodeStrings(record)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid sign group record")
+	}
+	return &SignGroup{Manager: fields[0], Members: fields[1:]}, nil
+}
+
+// AddSignGroup 智能合约中的方法,管理员登记签名组
+// @contract_arg gid: 签名组ID
+// @contract_arg manager: 管理方的伪ID,其公钥用于打开签名
+// @contract_arg members: 成员伪ID列表编码
+// @contract_arg r: 椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) AddSignGroup() protogo.Response {
+	gid := p.ReadArgs("gid")
+	manager := p.ReadArgs("manager")
+	members := p.ReadArgs("members")
+	err := p.VerifyAdmin(p.BytesCombine([]byte("AddSignGroup"), gid, manager, members), p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	if p.IsRegistered(string(gid)) {
+		return sdk.Error("id already registered")
+	}
+	if strings.HasPrefix(string(gid), CommitOwnerPrefix) {
+		return sdk.Error("id prefix reserved for commit owners:" + CommitOwnerPrefix)
+	}
+	if !p.HasState(p.BuildKey(PidDomain, string(manager))) {
+		return sdk.Error("manager pid not registered:" + string(manager))
+	}
+	memberList, err := utils.DecodeStrings(members)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if len(memberList) == 0 {
+		return sdk.Error("empty sign group")
+	}
+	seen := make(map[string]bool, len(memberList))
+	for _, member := range memberList {
+		if seen[member] {
+			return sdk.Error("duplicate member:" + member)
+		}
+		seen[member] = true
+		if !p.HasState(p.BuildKey(PidDomain, member)) {
+			return sdk.Error("member pid not registered:" + member)
+		}
+	}
+	record := utils.EncodeStrings(append([]string{string(manager)}, memberList...))
+	err = p.WriteState(p.BuildKey(SignGroupDomain, string(gid)), record)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("add sign group success"))
+}
+
+// ReadSignGroupValue 智能合约中的方法,查询签名组
+// @contract_arg gid: 签名组ID
+// 返回值为字符串列表编码:管理方伪ID,成员伪ID...
+func (p *OwnershipManagement) ReadSignGroupValue() protogo.Response {
+	record, err := p.ReadState(p.BuildKey(SignGroupDomain, string(p.ReadArgs("gid"))))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if len(record) == 0 {
+		return sdk.Error("no sign group")
+	}
+	return sdk.Success(record)
+}
+
+// VerifySignGroup 校验环签名来自签名组的某个成员
+func (p *OwnershipManagement) VerifySignGroup(group *SignGroup, content, sig []byte) error {
+	manager, err := p.readPk(group.Manager)
+	if err != nil {
+		return err
+	}
+	ring := make([]*ecdsa.PublicKey, len(group.Members))
+	for i, member := range group.Members {
+		ring[i], err = p.readPk(member)
+		if err != nil {
+			return err
+		}
+	}
+	return ringsig.Verify(content, ring, manager, sig)
+}
+
+func (p *OwnershipManagement) readPk(pid string) (*ecdsa.PublicKey, error) {
+	pkBytes, err := p.ReadPkByPid(pid)
+	if err != nil {
+		return nil, err
+	}
+	return ecdsa_pid.ParsePk(pkBytes)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/pb/protogo"
+	"chainmaker.org/chainmaker/contract-sdk-go/v2/sdk"
+	"fmt"
+	"transfer-contract-go/utils"
+)
+
+// 产品互换相关代码
+const (
+	SwapDomain = "swap."
+)
+
+// ProductSwap 发起方已承诺、等待对方完成的产品互换
+// 发起时不提交任何秘密值,双方的秘密值都在完成互换的交易中提交并校验
+type ProductSwap struct {
+	Owner        string
+	Tids         []string
+	Counterparty string
+	CounterTids  []string
+	ExpireHeight int
+}
+
+func (p *OwnershipManagement) ReadSwap(swapId string) (*ProductSwap, error) {
+	record, err := p.ReadState(p.BuildKey(SwapDomain, swapId))
+	if err != nil {
+		return nil, err
+	}
+	if len(record) == 0 {
+		return nil, fmt.Errorf("no swap:%s", swapId)
+	}
+	fields, err := utils.DecodeStrings(record)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid swap record")
+	}
+	swap := &ProductSwap{Owner: fields[0], Counterparty: fields[2]}
+	swap.Tids, err = utils.DecodeTid([]byte(fields[1]))
+	if err != nil {
+		return nil, err
+	}
+	swap.CounterTids, err = utils.DecodeTid([]byte(fields[3]))
+	if err != nil {
+		return nil, err
+	}
+	swap.ExpireHeight, err = parseOptionalInt(fields[4])
+	if err != nil {
+		return nil, err
+	}
+	return swap, nil
+}
+
+// ProposeSwap 智能合约中的方法,发起方承诺自己的产品批次并指定对方的产品批次
+// 互换ID为本交易的交易ID
+// @contract_arg owner: 发起方的伪ID
+// @contract_arg tid: 发起方的产品ID列表编码
+// @contract_arg counterparty: 对方的伪ID
+// @contract_arg counterTid: 对方的产品ID列表编码
+// @contract_arg expireHeight: 可选,互换失效的区块高度
+// @contract_arg tidSig: 发起方隐藏产品ID的产品密钥签名集合,见VerifyTidKnowledge
+// @contract_arg r: 发起方椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 发起方椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) ProposeSwap() protogo.Response {
+	owner := p.ReadArgs("owner")
+	allTids := p.ReadArgs("tid")
+	counterparty := p.ReadArgs("counterparty")
+	counterTids := p.ReadArgs("counterTid")
+	expireHeight := p.ReadArgs("expireHeight")
+	content := p.BytesCombine([]byte("ProposeSwap"), owner, allTids, counterparty, counterTids, expireHeight)
+	err := p.VerifyPid(string(owner), content, p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	if string(owner) == string(counterparty) {
+		return sdk.Error("cannot swap with self")
+	}
+	if _, err = parseOptionalInt(string(expireHeight)); err != nil {
+		return sdk.Error(err.Error())
+	}
+	tidList, err := utils.DecodeTid(allTids)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	counterTidList, err := utils.DecodeTid(counterTids)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	err = p.verifyOwnerOf(string(owner), tidList)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.verifyOwnerOf(string(counterparty), counterTidList)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	err = p.VerifyTidKnowledge(tidList, content, p.ReadArgs("tidSig"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	swapId, err := sdk.Instance.GetTxId()
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	record := utils.EncodeStrings([]string{string(owner), string(allTids), string(counterparty), string(counterTids), string(expireHeight)})
+	err = p.WriteState(p.BuildKey(SwapDomain, swapId), record)
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	sdk.Instance.EmitEvent("ProposeSwap", []string{swapId, string(owner), string(counterparty)})
+	return sdk.Success([]byte(swapId))
+}
+
+// CompleteSwap 智能合约中的方法,对方承诺自己的产品批次,两批产品在同一交易中互换
+// 只有双方的签名与双方的承诺校验全部通过时才会修改所有者
+// @contract_arg swap: 互换ID
+// @contract_arg pSecret: 对方批次聚合的秘密值
+// @contract_arg opening: 对方批次聚合的盲因子
+// @contract_arg ownerSecret: 发起方批次聚合的秘密值,由发起方线下交给对方
+// @contract_arg ownerOpening: 发起方批次聚合的盲因子
+// @contract_arg tidSig: 对方隐藏产品ID的产品密钥签名集合,见VerifyTidKnowledge
+// @contract_arg r: 对方椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 对方椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) CompleteSwap() protogo.Response {
+	swapId := p.ReadArgs("swap")
+	pSecret := p.ReadArgs("pSecret")
+	opening := p.ReadArgs("opening")
+	ownerSecret := p.ReadArgs("ownerSecret")
+	ownerOpening := p.ReadArgs("ownerOpening")
+	swap, err := p.ReadSwap(string(swapId))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	content := p.BytesCombine([]byte("CompleteSwap"), swapId, pSecret, opening, ownerSecret, ownerOpening)
+	err = p.VerifyPid(swap.Counterparty, content, p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	if swap.ExpireHeight > 0 {
+		height, err := sdk.Instance.GetBlockHeight()
+		if err != nil {
+			return sdk.Error(err.Error())
+		}
+		if height > swap.ExpireHeight {
+			return sdk.Error("swap expired")
+		}
+	}
+	err = p.verifyOwnerOf(swap.Owner, swap.Tids)
+	if err != nil {
+		return sdk.Error("swap outdated:" + err.Error())
+	}
+	err = p.verifyOwnerOf(swap.Counterparty, swap.CounterTids)
+	if err != nil {
+		return sdk.Error("swap outdated:" + err.Error())
+	}
+	err = p.VerifyTidKnowledge(swap.CounterTids, content, p.ReadArgs("tidSig"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.VerifyBatchSecret(swap.Tids, ownerSecret, ownerOpening)
+	if err != nil {
+		return sdk.Error("permission deny when complete swap:" + err.Error())
+	}
+	err = p.VerifyBatchSecret(swap.CounterTids, pSecret, opening)
+	if err != nil {
+		return sdk.Error("permission deny when complete swap:" + err.Error())
+	}
+	err = p.CheckTransferPolicy(swap.Tids, swap.Counterparty)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.CheckTransferPolicy(swap.CounterTids, swap.Owner)
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.CheckAttestations(append(swap.Tids, swap.CounterTids...))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	for _, tid := range swap.Tids {
+		err := p.WriteOwner(tid, swap.Counterparty)
+		if err != nil {
+			return sdk.Error(err.Error())
+		}
+	}
+	for _, tid := range swap.CounterTids {
+		err := p.WriteOwner(tid, swap.Owner)
+		if err != nil {
+			return sdk.Error(err.Error())
+		}
+	}
+	err = p.DeleteState(p.BuildKey(SwapDomain, string(swapId)))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("swap product success"))
+}
+
+// CancelSwap 智能合约中的方法,任意一方取消尚未完成的互换
+// @contract_arg swap: 互换ID
+// @contract_arg pid: 取消方的伪ID
+// @contract_arg r: 取消方椭圆曲线签名中的r,十进制整数文本形式
+// @contract_arg s: 取消方椭圆曲线签名中的s，十进制整数文本形式
+func (p *OwnershipManagement) CancelSwap() protogo.Response {
+	swapId := p.ReadArgs("swap")
+	pid := string(p.ReadArgs("pid"))
+	swap, err := p.ReadSwap(string(swapId))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if pid != swap.Owner && pid != swap.Counterparty {
+		return sdk.Error("permission deny:not a party of swap")
+	}
+	err = p.VerifyPid(pid, p.BytesCombine([]byte("CancelSwap"), swapId), p.ReadArgs("r"), p.ReadArgs("s"))
+	if err != nil {
+		return sdk.Error("permission deny:" + err.Error())
+	}
+	err = p.DeleteState(p.BuildKey(SwapDomain, string(swapId)))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	return sdk.Success([]byte("cancel swap success"))
+}
+
+// ReadSwapValue 智能合约中的方法,查询互换
+// @contract_arg swap: 互换ID
+// 返回值为字符串列表编码:发起方,发起方产品ID列表编码,对方,对方产品ID列表编码,失效区块高度
+func (p *OwnershipManagement) ReadSwapValue() protogo.Response {
+	record, err := p.ReadState(p.BuildKey(SwapDomain, string(p.ReadArgs("swap"))))
+	if err != nil {
+		return sdk.Error(err.Error())
+	}
+	if len(record) == 0 {
+		return sdk.Error("no swap")
+	}
+	return sdk.Success(record)
+}
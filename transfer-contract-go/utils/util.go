@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/binary"
+	"fmt"
 )
 
 func CalcSha256(content []byte) []byte {
@@ -13,27 +14,50 @@ func CalcSha256(content []byte) []byte {
 }
 
 func DecodeTid(tids []byte) ([]string, error) {
-	reader := bytes.NewReader(tids)
+	return DecodeStrings(tids)
+}
+
+// DecodeStrings 解析int32长度前缀编码的字符串列表
+func DecodeStrings(content []byte) ([]string, error) {
+	reader := bytes.NewReader(content)
 	var length int32
 	err := binary.Read(reader, binary.BigEndian, &length)
 	if err != nil {
 		return nil, err
 	}
-	tidList := make([]string, length)
+	// 每项至少有4字节的长度前缀,超过剩余字节数/4的长度不可能合法,避免按伪造的长度预分配
+	if length < 0 || int(length) > reader.Len()/4 {
+		return nil, fmt.Errorf("invalid list length:%d", length)
+	}
+	list := make([]string, 0, length)
 	for i := 0; i < int(length); i++ {
-		var tidLen int32
-		err := binary.Read(reader, binary.BigEndian, &tidLen)
+		var itemLen int32
+		err := binary.Read(reader, binary.BigEndian, &itemLen)
 		if err != nil {
 			return nil, err
 		}
-		tid := make([]byte, tidLen)
-		err = binary.Read(reader, binary.BigEndian, tid)
+		if itemLen < 0 || int(itemLen) > reader.Len() {
+			return nil, fmt.Errorf("invalid item length:%d", itemLen)
+		}
+		item := make([]byte, itemLen)
+		err = binary.Read(reader, binary.BigEndian, item)
 		if err != nil {
 			return nil, err
 		}
-		tidList[i] = string(tid)
+		list = append(list, string(item))
+	}
+	return list, nil
+}
+
+// EncodeStrings 以int32长度前缀编码字符串列表,与DecodeStrings对应
+func EncodeStrings(list []string) []byte {
+	buffer := bytes.NewBuffer([]byte{})
+	_ = binary.Write(buffer, binary.BigEndian, int32(len(list)))
+	for _, item := range list {
+		_ = binary.Write(buffer, binary.BigEndian, int32(len(item)))
+		buffer.WriteString(item)
 	}
-	return tidList, nil
+	return buffer.Bytes()
 }
 
 func BytesCombine(pBytes ...[]byte) []byte {